@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// generateAccessors writes a getter and a setter for each unexported target field in config.Accessors, eg:
+// Secret() and SetSecret(v) for secret
+func (g *Generator) generateAccessors(config types.MappingConfig) {
+	targetType := g.targetRef(&config, config)
+
+	for _, name := range config.Accessors {
+		var targetField *types.FieldInfo
+		for i := range config.TargetType.Fields {
+			if config.TargetType.Fields[i].Name == name {
+				targetField = &config.TargetType.Fields[i]
+			}
+		}
+		if targetField == nil {
+			continue
+		}
+		sourceField, sourceConfig, ok := g.findSourceField(*targetField, config)
+		if !ok {
+			continue
+		}
+		typeStr, _ := g.fieldTargetType(sourceField, *targetField, sourceConfig)
+
+		getter := strings.ToUpper(name[:1]) + name[1:]
+		fmt.Fprintf(g.buf, "// %s returns %s\n", getter, name)
+		fmt.Fprintf(g.buf, "func (t *%s) %s() %s {\n", targetType, getter, typeStr)
+		fmt.Fprintf(g.buf, "\treturn t.%s\n", name)
+		g.buf.WriteString("}\n\n")
+
+		fmt.Fprintf(g.buf, "// Set%s sets %s\n", getter, name)
+		fmt.Fprintf(g.buf, "func (t *%s) Set%s(v %s) {\n", targetType, getter, typeStr)
+		fmt.Fprintf(g.buf, "\tt.%s = v\n", name)
+		g.buf.WriteString("}\n\n")
+	}
+}
+
+// accessible reports whether the code generated for config can read and write the field name of a struct
+func accessible(name string, info *types.StructInfo, config types.MappingConfig) bool {
+	return token.IsExported(name) || info == nil || info.PackagePath == config.OutputPath
+}
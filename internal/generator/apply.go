@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// generateApplyMethod writes ApplyTo, which writes the mapped fields onto an existing source value
+//
+// Source fields the target doesn't carry are left intact, as are fields whose target field is zero with ApplySkipZero
+func (g *Generator) generateApplyMethod(config types.MappingConfig) {
+	targetType := config.TargetType.TypeName
+	sourceType := config.SourceType.TypeName
+	sourceRef := g.sourceRef(config)
+	targetRef := g.targetRef(&config, config)
+
+	name := "ApplyTo"
+	if config.ApplyFuncName != "" {
+		name = config.ApplyFuncName
+	}
+	fmt.Fprintf(g.buf, "// %s writes the mapped fields onto an external struct, leaving fields the local struct doesn't carry intact\n", name)
+	if config.ApplySkipZero {
+		g.buf.WriteString("//\n")
+		g.buf.WriteString("// Zero fields are skipped, eg: for partial updates\n")
+	}
+	g.buf.WriteString("//\n")
+
+	if config.ApplyFuncName != "" {
+		fmt.Fprintf(g.buf, "// Usage: %s(&local%s, &external%s)\n", config.ApplyFuncName, targetType, sourceType)
+		fmt.Fprintf(g.buf, "func %s%s(t *%s, dst *%s) {\n", config.ApplyFuncName, g.typeParamsDecl(config), targetRef, sourceRef)
+	} else {
+		fmt.Fprintf(g.buf, "// Usage: local%s.ApplyTo(&external%s)\n", targetType, sourceType)
+		fmt.Fprintf(g.buf, "func (t *%s) ApplyTo(dst *%s) {\n", targetRef, sourceRef)
+	}
+
+	// without zero checks every pointer only needs allocating once
+	var allocated map[string]bool
+	if !config.ApplySkipZero {
+		allocated = make(map[string]bool)
+	}
+	g.generateApplyFields(config, config, "", allocated)
+
+	// target-only fields with a To func are applied last, as in To
+	for _, field := range config.ExtraFields {
+		if field.ToFunc != "" {
+			fmt.Fprintf(g.buf, "\t%s(t.%s, dst)\n", field.ToFunc, field.Name)
+		}
+	}
+
+	g.buf.WriteString("}\n\n")
+}
+
+// generateApplyFields writes an assignment to dst for every mapped field of the source struct at path
+//
+// allocated tracks the dst pointers already allocated at the top level of the method, nil if there are none
+func (g *Generator) generateApplyFields(config, sourceConfig types.MappingConfig, path string, allocated map[string]bool) {
+	for _, sourceField := range sourceConfig.SourceType.Fields {
+		fieldPath := path + sourceField.Name
+
+		if targetField := g.findTargetField(fieldPath, config); targetField != nil {
+			g.generateApplyField(*targetField, sourceField, fieldPath, config, sourceConfig, allocated)
+			continue
+		}
+
+		if nestedInfo, ok := config.NestedTypes[fieldPath]; ok {
+			nestedConfig := config
+			nestedConfig.SourceType = nestedInfo
+			g.generateApplyFields(config, nestedConfig, fieldPath+".", allocated)
+		}
+
+		// fields the target doesn't carry are left intact
+	}
+}
+
+// generateApplyField writes the assignment of a single target field to its source field path in dst
+func (g *Generator) generateApplyField(tf, sf types.FieldInfo, sourcePath string, config, sourceConfig types.MappingConfig, allocated map[string]bool) {
+	value := "t." + tf.Name
+	dst := "dst." + sourcePath
+	sourceExpr := parseType(sf.Type)
+	_, deref := config.Derefs[tf.Source]
+	_, optional := config.Optionals[tf.Source]
+
+	// registered nested types with their own ApplyTo are applied recursively, they skip zero fields themselves
+	if mapping := g.applyMapping(sourceExpr, sourceConfig); mapping != nil && !deref && !optional {
+		g.writeAllocations("\t", sourcePath, config, allocated)
+		if !sf.IsPointer {
+			fmt.Fprintf(g.buf, "\t%s\n", g.applyCall(mapping, value, dst, false))
+			return
+		}
+
+		fmt.Fprintf(g.buf, "\tif %s != nil {\n", value)
+		fmt.Fprintf(g.buf, "\t\tif %s == nil {\n", dst)
+		fmt.Fprintf(g.buf, "\t\t\t%s = &%s{}\n", dst, g.sourceTypeName(strings.TrimPrefix(sf.Type, "*"), sourceConfig))
+		g.buf.WriteString("\t\t}\n")
+		fmt.Fprintf(g.buf, "\t\t%s\n", g.applyCall(mapping, value, dst, true))
+		if config.ApplySkipZero {
+			g.buf.WriteString("\t}\n")
+			return
+		}
+		g.buf.WriteString("\t} else {\n")
+		fmt.Fprintf(g.buf, "\t\t%s = nil\n", dst)
+		g.buf.WriteString("\t}\n")
+		return
+	}
+
+	indent := "\t"
+	if config.ApplySkipZero {
+		mirrored := mirroredField(sf, tf, config)
+		check := g.nonZeroCheck(value, parseType(mirrored.Type), mirrored.Underlying, sourceConfig)
+		if enumMap, ok := config.EnumMaps[tf.Source]; ok {
+			check = g.nonZeroCheck(value, ast.NewIdent(enumMap.Underlying), "", sourceConfig)
+		}
+		if optional {
+			check = g.presentCheck(value, config)
+		}
+		fmt.Fprintf(g.buf, "\tif %s {\n", check)
+		indent = "\t\t"
+	}
+
+	g.writeAllocations(indent, sourcePath, config, allocated)
+	fmt.Fprintf(g.buf, "%s%s = %s\n", indent, dst, g.generateReverseFieldMapping(tf, sf, sourceConfig))
+
+	if config.ApplySkipZero {
+		g.buf.WriteString("\t}\n")
+	}
+}
+
+// writeAllocations allocates the nil pointers on the way to a nested source field in dst, skipping those in allocated
+func (g *Generator) writeAllocations(indent, sourcePath string, config types.MappingConfig, allocated map[string]bool) {
+	parts := strings.Split(sourcePath, ".")
+	structConfig := config
+	for i := 0; i < len(parts)-1 && structConfig.SourceType != nil; i++ {
+		path := strings.Join(parts[:i+1], ".")
+		for _, field := range structConfig.SourceType.Fields {
+			if field.Name != parts[i] || !field.IsPointer || allocated[path] {
+				continue
+			}
+			if allocated != nil {
+				allocated[path] = true
+			}
+			fmt.Fprintf(g.buf, "%sif dst.%s == nil {\n", indent, path)
+			fmt.Fprintf(g.buf, "%s\tdst.%s = &%s{}\n", indent, path,
+				g.sourceTypeName(strings.TrimPrefix(field.Type, "*"), structConfig))
+			fmt.Fprintf(g.buf, "%s}\n", indent)
+		}
+		structConfig.SourceType = config.NestedTypes[path]
+	}
+}
+
+// applyMapping returns the registered mapping with an ApplyTo a source type (or pointer to it) is converted with
+func (g *Generator) applyMapping(sourceExpr ast.Expr, config types.MappingConfig) *types.MappingConfig {
+	if star, ok := sourceExpr.(*ast.StarExpr); ok {
+		sourceExpr = star.X
+	}
+	if mapping := g.mappingOf(sourceExpr, config); mapping != nil && mapping.ApplyTo {
+		return mapping
+	}
+	return nil
+}
+
+// applyCall returns a call applying a target value onto dst with a registered mapping
+//
+// value and dst must be addressable unless isPtr
+func (g *Generator) applyCall(mapping *types.MappingConfig, value, dst string, isPtr bool) string {
+	if !isPtr {
+		dst = "&" + dst
+	}
+	if mapping.ApplyFuncName != "" {
+		if !isPtr {
+			value = "&" + value
+		}
+		return fmt.Sprintf("%s(%s, %s)", mapping.ApplyFuncName, value, dst)
+	}
+	return fmt.Sprintf("%s.ApplyTo(%s)", value, dst)
+}
+
+// nonZeroCheck returns a condition that holds when value (of the target type mapped from sourceExpr) isn't zero
+//
+// underlying describes named source types, see FieldInfo.Underlying
+func (g *Generator) nonZeroCheck(value string, sourceExpr ast.Expr, underlying string, config types.MappingConfig) string {
+	// named basic types, eg: type Status string
+	if predeclared[underlying] {
+		sourceExpr = ast.NewIdent(underlying)
+	}
+	if g.isTime(sourceExpr, config) {
+		return fmt.Sprintf("!%s.IsZero()", value)
+	}
+
+	switch t := sourceExpr.(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return value + " != nil"
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return value + " != nil"
+		}
+	case *ast.Ident:
+		switch t.Name {
+		case "bool":
+			return value
+		case "string":
+			return value + ` != ""`
+		case "error", "any":
+			return value + " != nil"
+		}
+		if predeclared[t.Name] {
+			return value + " != 0"
+		}
+	}
+
+	// named types may not be comparable
+	return fmt.Sprintf("!%s.ValueOf(%s).IsZero()", g.imports.alias("reflect", "reflect"), value)
+}
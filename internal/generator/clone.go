@@ -0,0 +1,268 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// generateCloneMethod writes Clone, which deep copies the target
+func (g *Generator) generateCloneMethod(config types.MappingConfig) {
+	targetType := g.targetRef(&config, config)
+
+	g.buf.WriteString("// Clone returns a deep copy, registered nested types are copied with their own Clone if they have one, and\n")
+	g.buf.WriteString("// structs without one field by field\n")
+	g.buf.WriteString("//\n")
+	g.buf.WriteString("// Target-only fields, interfaces and unexported fields of other packages (eg: time.Time's) are copied as is\n")
+	fmt.Fprintf(g.buf, "func (t *%s) Clone() *%s {\n", targetType, targetType)
+	g.buf.WriteString("\tif t == nil {\n")
+	g.buf.WriteString("\t\treturn nil\n")
+	g.buf.WriteString("\t}\n\n")
+	g.buf.WriteString("\tclone := *t\n")
+
+	fields, paths := g.mappedFields(config)
+	for i, targetField := range fields {
+		sourceField, sourceConfig, ok := g.findSourceField(targetField, config)
+		if !ok {
+			continue
+		}
+		sourceField = mirroredField(sourceField, targetField, config)
+		if cloneExpr, ok := g.cloneExpr("t."+paths[i], parseType(sourceField.Type), sourceConfig); ok {
+			fmt.Fprintf(g.buf, "\tclone.%s = %s\n", paths[i], cloneExpr)
+		}
+	}
+
+	g.buf.WriteString("\treturn &clone\n")
+	g.buf.WriteString("}\n\n")
+}
+
+// cloneExpr returns an expression deep copying value (of the target type mapped from sourceExpr),
+// false if assigning it already copies everything modelgen knows about
+//
+// value must be addressable
+func (g *Generator) cloneExpr(value string, sourceExpr ast.Expr, config types.MappingConfig) (string, bool) {
+	if mapping, isPtr := g.cloneMapping(sourceExpr, config); mapping != nil {
+		if isPtr {
+			return value + ".Clone()", true
+		}
+		return fmt.Sprintf("*%s.Clone()", receiver(value)), true
+	}
+	if mapping := g.mappingOf(sourceExpr, config); mapping != nil && mapping.Enum == nil && g.helpers != nil {
+		if name, ok := g.cloneFunc(mapping); ok {
+			return name + "(" + value + ")", true
+		}
+		return "", false
+	}
+	if info := g.structOf(sourceExpr, config); info != nil && g.helpers != nil {
+		if name, ok := g.cloneStructFunc(info, sourceExpr, config); ok {
+			return name + "(" + value + ")", true
+		}
+		return "", false
+	}
+
+	switch t := sourceExpr.(type) {
+	case *ast.StarExpr:
+		elemExpr, ok := g.cloneExpr("*v", t.X, config)
+		if !ok {
+			elemExpr = "*v"
+		}
+		return fmt.Sprintf(`func(v %s) %s {
+		if v == nil {
+			return nil
+		}
+		clone := %s
+		return &clone
+	}(%s)`, g.targetType(t, config), g.targetType(t, config), elemExpr, value), true
+
+	case *ast.ArrayType:
+		elemExpr, ok := g.cloneExpr("v[i]", t.Elt, config)
+		if t.Len != nil {
+			if !ok {
+				return "", false
+			}
+			return fmt.Sprintf(`func(v %s) %s {
+		for i := range v {
+			v[i] = %s
+		}
+		return v
+	}(%s)`, g.targetType(t, config), g.targetType(t, config), elemExpr, value), true
+		}
+
+		if !ok {
+			return fmt.Sprintf(`func(v %s) %s {
+		if v == nil {
+			return nil
+		}
+		clone := make(%s, len(v))
+		copy(clone, v)
+		return clone
+	}(%s)`, g.targetType(t, config), g.targetType(t, config), g.targetType(t, config), value), true
+		}
+		return fmt.Sprintf(`func(v %s) %s {
+		if v == nil {
+			return nil
+		}
+		clone := make(%s, len(v))
+		for i := range v {
+			clone[i] = %s
+		}
+		return clone
+	}(%s)`, g.targetType(t, config), g.targetType(t, config), g.targetType(t, config), elemExpr, value), true
+
+	case *ast.MapType:
+		elemExpr, ok := g.cloneExpr("item", t.Value, config)
+		if !ok {
+			elemExpr = "item"
+		}
+		return fmt.Sprintf(`func(v %s) %s {
+		if v == nil {
+			return nil
+		}
+		clone := make(%s, len(v))
+		for key, item := range v {
+			clone[key] = %s
+		}
+		return clone
+	}(%s)`, g.targetType(t, config), g.targetType(t, config), g.targetType(t, config), elemExpr, value), true
+	}
+
+	return "", false
+}
+
+// cloneMapping returns the registered mapping with Clone a source type (or pointer to it) is converted with
+func (g *Generator) cloneMapping(sourceExpr ast.Expr, config types.MappingConfig) (*types.MappingConfig, bool) {
+	star, isPtr := sourceExpr.(*ast.StarExpr)
+	if isPtr {
+		sourceExpr = star.X
+	}
+	if mapping := g.mappingOf(sourceExpr, config); mapping != nil && mapping.Clone {
+		return mapping, isPtr
+	}
+	return nil, false
+}
+
+// cloneFunc returns the helper deep copying the target of a registered nested mapping without Clone field by
+// field, false if assigning it already copies everything
+//
+// Helpers are named after the nested target, eg: cloneAccountPost while generating Account, and written at the end
+// of the file
+func (g *Generator) cloneFunc(mapping *types.MappingConfig) (string, bool) {
+	name := "clone" + g.helpers.owner + mapping.TargetType.TypeName
+	if needed, ok := g.helpers.names[name]; ok {
+		return name, needed
+	}
+
+	// types referencing themselves always need a helper, their references are slices, maps or pointers
+	g.helpers.names[name] = true
+	defer g.helperTypeParams(mapping)()
+
+	var body bytes.Buffer
+	fields, paths := g.mappedFields(*mapping)
+	for i, targetField := range fields {
+		sourceField, sourceConfig, ok := g.findSourceField(targetField, *mapping)
+		if !ok {
+			continue
+		}
+		sourceField = mirroredField(sourceField, targetField, *mapping)
+		if cloneExpr, ok := g.cloneExpr("v."+paths[i], parseType(sourceField.Type), sourceConfig); ok {
+			fmt.Fprintf(&body, "\tv.%s = %s\n", paths[i], cloneExpr)
+		}
+	}
+	if body.Len() == 0 {
+		g.helpers.names[name] = false
+		return "", false
+	}
+
+	targetType := g.targetRef(mapping, *mapping)
+	buf := g.helpers.buf
+	fmt.Fprintf(buf, "// %s deep copies %s\n", name, mapping.TargetType.TypeName)
+	fmt.Fprintf(buf, "func %s%s(v %s) %s {\n", name, g.typeParamsDecl(*mapping), targetType, targetType)
+	buf.Write(body.Bytes())
+	buf.WriteString("\treturn v\n")
+	buf.WriteString("}\n\n")
+	return name, true
+}
+
+// structOf returns the fields of an unregistered struct type written in a source field type, nil if they weren't read
+func (g *Generator) structOf(sourceExpr ast.Expr, config types.MappingConfig) *types.StructInfo {
+	switch sourceExpr.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		return config.SourceType.Structs[exprString(sourceExpr)]
+	}
+	return nil
+}
+
+// cloneStructFunc returns the helper deep copying an unregistered struct field by field, false if assigning it
+// already copies everything it can reach
+//
+// Helpers are named after the struct's package, eg: cloneAccountApiPlain for api.Plain while generating Account.
+// Unexported fields of other packages are copied as is
+func (g *Generator) cloneStructFunc(info *types.StructInfo, sourceExpr ast.Expr, config types.MappingConfig) (string, bool) {
+	pkgName := strings.ToUpper(info.PackageName[:1]) + info.PackageName[1:]
+	name := "clone" + g.helpers.owner + pkgName + info.TypeName
+	if needed, ok := g.helpers.names[name]; ok {
+		return name, needed
+	}
+
+	g.helpers.names[name] = true
+	typeParams := g.typeParams
+	g.typeParams = make(map[string]bool)
+	defer func() { g.typeParams = typeParams }()
+
+	structConfig := config
+	structConfig.SourceType = info
+	var body bytes.Buffer
+	for _, field := range info.Fields {
+		if !accessible(field.Name, info, config) {
+			continue
+		}
+		if cloneExpr, ok := g.cloneExpr("v."+field.Name, parseType(field.Type), structConfig); ok {
+			fmt.Fprintf(&body, "\tv.%s = %s\n", field.Name, cloneExpr)
+		}
+	}
+	if body.Len() == 0 {
+		g.helpers.names[name] = false
+		return "", false
+	}
+
+	typeStr := g.targetType(sourceExpr, config)
+	buf := g.helpers.buf
+	fmt.Fprintf(buf, "// %s deep copies %s.%s\n", name, info.PackageName, info.TypeName)
+	fmt.Fprintf(buf, "func %s(v %s) %s {\n", name, typeStr, typeStr)
+	buf.Write(body.Bytes())
+	buf.WriteString("\treturn v\n")
+	buf.WriteString("}\n\n")
+	return name, true
+}
+
+// helperFuncs are the helpers written at the end of a generated file, for nested types without the generated methods
+type helperFuncs struct {
+	owner string          // target the file is generated for, helpers are named after it
+	buf   *bytes.Buffer   // helpers written so far
+	names map[string]bool // helpers by name, false if the type didn't need one
+
+	walked map[*types.MappingConfig]bool // nested mappings Diff is walking the fields of, see generateNestedDiff
+}
+
+func newHelperFuncs(config types.MappingConfig) *helperFuncs {
+	return &helperFuncs{
+		owner:  config.TargetType.TypeName,
+		buf:    &bytes.Buffer{},
+		names:  make(map[string]bool),
+		walked: make(map[*types.MappingConfig]bool),
+	}
+}
+
+// helperTypeParams switches to the type parameters of a nested mapping while its helper is generated,
+// returns a func switching back
+func (g *Generator) helperTypeParams(mapping *types.MappingConfig) func() {
+	typeParams := g.typeParams
+	g.typeParams = make(map[string]bool)
+	for _, param := range mapping.SourceType.TypeParams {
+		g.typeParams[param.Name] = true
+	}
+	return func() { g.typeParams = typeParams }
+}
@@ -0,0 +1,343 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// FieldChangeType is the type Diff reports changes with, generated once per target package
+const FieldChangeType = "FieldChange"
+
+// GenerateFieldChange generates the FieldChange type without package/imports
+func (g *Generator) GenerateFieldChange() string {
+	g.imports = newImportPlanner()
+
+	return fmt.Sprintf(`// %[1]s is a field that differs between two models, see Diff
+type %[1]s struct {
+	Path string // path of the field within the model, eg: "Settings.Theme"
+	Old  any
+	New  any
+}
+`, FieldChangeType)
+}
+
+// mappedFields returns the target fields mapped from a source field along with their path in the target,
+// grouped fields included
+func (g *Generator) mappedFields(config types.MappingConfig) ([]types.FieldInfo, []string) {
+	var fields []types.FieldInfo
+	var paths []string
+	for _, targetField := range config.TargetType.Fields {
+		if group := g.findGroup(targetField.Name, config); targetField.Source == "" && group != nil {
+			for _, groupField := range group.Type.Fields {
+				fields = append(fields, groupField)
+				paths = append(paths, group.Name+"."+groupField.Name)
+			}
+			continue
+		}
+		if targetField.Source != "" {
+			fields = append(fields, targetField)
+			paths = append(paths, targetField.Name)
+		}
+	}
+	return fields, paths
+}
+
+// generateCompareMethods writes Equal and Diff, the helpers comparing registered nested types without them are
+// written at the end of the file
+func (g *Generator) generateCompareMethods(config types.MappingConfig) {
+	g.generateEqualMethod(config)
+	g.generateDiffMethod(config)
+}
+
+// generateEqualMethod writes Equal, which compares the mapped fields of two targets
+func (g *Generator) generateEqualMethod(config types.MappingConfig) {
+	targetType := g.targetRef(&config, config)
+
+	g.buf.WriteString("// Equal reports whether both models hold the same mapped values, target-only fields are ignored\n")
+	fmt.Fprintf(g.buf, "func (t *%s) Equal(other *%s) bool {\n", targetType, targetType)
+	g.buf.WriteString("\tif t == nil || other == nil {\n")
+	g.buf.WriteString("\t\treturn t == other\n")
+	g.buf.WriteString("\t}\n")
+
+	fields, paths := g.mappedFields(config)
+	for i, targetField := range fields {
+		sourceField, sourceConfig, ok := g.findSourceField(targetField, config)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(g.buf, "\tif %s {\n", g.differs("t."+paths[i], "other."+paths[i], comparedType(mirroredField(sourceField, targetField, config)), sourceConfig))
+		g.buf.WriteString("\t\treturn false\n")
+		g.buf.WriteString("\t}\n")
+	}
+
+	g.buf.WriteString("\treturn true\n")
+	g.buf.WriteString("}\n\n")
+}
+
+// generateDiffMethod writes Diff, which lists the mapped fields that differ between two targets
+func (g *Generator) generateDiffMethod(config types.MappingConfig) {
+	targetType := g.targetRef(&config, config)
+
+	g.buf.WriteString("// Diff lists the mapped fields that differ from other, with their value in t as Old and in other as New\n")
+	g.buf.WriteString("//\n")
+	g.buf.WriteString("// Registered nested types are compared field by field, anything else as a whole. Path is empty if either model is nil\n")
+	fmt.Fprintf(g.buf, "func (t *%s) Diff(other *%s) []%s {\n", targetType, targetType, FieldChangeType)
+	g.buf.WriteString("\tif t == nil || other == nil {\n")
+	g.buf.WriteString("\t\tif t != other {\n")
+	fmt.Fprintf(g.buf, "\t\t\treturn []%s{{Old: t, New: other}}\n", FieldChangeType)
+	g.buf.WriteString("\t\t}\n")
+	g.buf.WriteString("\t\treturn nil\n")
+	g.buf.WriteString("\t}\n\n")
+	fmt.Fprintf(g.buf, "\tvar changes []%s\n", FieldChangeType)
+
+	fields, paths := g.mappedFields(config)
+	for i, targetField := range fields {
+		sourceField, sourceConfig, ok := g.findSourceField(targetField, config)
+		if !ok {
+			continue
+		}
+		g.generateFieldDiff(paths[i], comparedType(mirroredField(sourceField, targetField, config)), sourceConfig)
+	}
+
+	g.buf.WriteString("\treturn changes\n")
+	g.buf.WriteString("}\n\n")
+}
+
+// generateFieldDiff writes the comparison of a single field, descending into registered nested types with Diff
+func (g *Generator) generateFieldDiff(path string, sourceExpr ast.Expr, config types.MappingConfig) {
+	value, other := "t."+path, "other."+path
+
+	mapping, isPtr := g.compareMapping(sourceExpr, config)
+	if mapping == nil {
+		if nested, isPtr := g.walkedMapping(sourceExpr, config); nested != nil {
+			g.generateNestedDiff(path, nested, isPtr)
+			return
+		}
+		fmt.Fprintf(g.buf, "\tif %s {\n", g.differs(value, other, sourceExpr, config))
+		fmt.Fprintf(g.buf, "\t\tchanges = append(changes, %s{Path: %q, Old: %s, New: %s})\n", FieldChangeType, path, value, other)
+		g.buf.WriteString("\t}\n")
+		return
+	}
+
+	nested := fmt.Sprintf("%s.Diff(&%s)", value, other)
+	if isPtr {
+		// nil pointers are reported as a whole
+		fmt.Fprintf(g.buf, "\tif %s == nil || %s == nil {\n", value, other)
+		fmt.Fprintf(g.buf, "\t\tif %s != %s {\n", value, other)
+		fmt.Fprintf(g.buf, "\t\t\tchanges = append(changes, %s{Path: %q, Old: %s, New: %s})\n", FieldChangeType, path, value, other)
+		g.buf.WriteString("\t\t}\n")
+		g.buf.WriteString("\t} else {\n")
+		nested = fmt.Sprintf("%s.Diff(%s)", value, other)
+	}
+	fmt.Fprintf(g.buf, "\tfor _, change := range %s {\n", nested)
+	fmt.Fprintf(g.buf, "\t\tchange.Path = %q + change.Path\n", path+".")
+	g.buf.WriteString("\t\tchanges = append(changes, change)\n")
+	g.buf.WriteString("\t}\n")
+	if isPtr {
+		g.buf.WriteString("\t}\n")
+	}
+}
+
+// generateNestedDiff writes the comparison of the fields of a registered nested type without Diff, nil pointers
+// are reported as a whole
+func (g *Generator) generateNestedDiff(path string, mapping *types.MappingConfig, isPtr bool) {
+	value, other := "t."+path, "other."+path
+	if isPtr {
+		fmt.Fprintf(g.buf, "\tif %s == nil || %s == nil {\n", value, other)
+		fmt.Fprintf(g.buf, "\t\tif %s != %s {\n", value, other)
+		fmt.Fprintf(g.buf, "\t\t\tchanges = append(changes, %s{Path: %q, Old: %s, New: %s})\n", FieldChangeType, path, value, other)
+		g.buf.WriteString("\t\t}\n")
+		g.buf.WriteString("\t} else {\n")
+	}
+
+	g.helpers.walked[mapping] = true
+	fields, paths := g.mappedFields(*mapping)
+	for i, targetField := range fields {
+		sourceField, sourceConfig, ok := g.findSourceField(targetField, *mapping)
+		if !ok {
+			continue
+		}
+		g.generateFieldDiff(path+"."+paths[i], comparedType(mirroredField(sourceField, targetField, *mapping)), sourceConfig)
+	}
+	delete(g.helpers.walked, mapping)
+
+	if isPtr {
+		g.buf.WriteString("\t}\n")
+	}
+}
+
+// walkedMapping returns the registered mapping without Equal and Diff a source type (or pointer to it) is converted
+// with, for Diff to walk its fields; nil for types referencing themselves, which are compared as a whole
+func (g *Generator) walkedMapping(sourceExpr ast.Expr, config types.MappingConfig) (*types.MappingConfig, bool) {
+	star, isPtr := sourceExpr.(*ast.StarExpr)
+	if isPtr {
+		sourceExpr = star.X
+	}
+	mapping := g.mappingOf(sourceExpr, config)
+	if mapping == nil || mapping.Enum != nil || mapping.Compare || g.helpers == nil || g.helpers.walked[mapping] {
+		return nil, false
+	}
+	return mapping, isPtr
+}
+
+// differs returns a condition that holds when two target values (of the target type mapped from sourceExpr) differ
+func (g *Generator) differs(a, b string, sourceExpr ast.Expr, config types.MappingConfig) string {
+	if g.comparable(sourceExpr, config) {
+		return fmt.Sprintf("%s != %s", a, b)
+	}
+	return "!" + g.equal(a, b, sourceExpr, config)
+}
+
+// equal returns an expression comparing two target values (of the target type mapped from sourceExpr)
+//
+// Registered nested types are compared with their Equal, field by field without one. Other types are compared with ==
+// if they're comparable, element by element if they're pointers, slices or maps, with reflect.DeepEqual otherwise
+func (g *Generator) equal(a, b string, sourceExpr ast.Expr, config types.MappingConfig) string {
+	if mapping, isPtr := g.compareMapping(sourceExpr, config); mapping != nil {
+		if isPtr {
+			return fmt.Sprintf("%s.Equal(%s)", a, b)
+		}
+		return fmt.Sprintf("%s.Equal(&%s)", receiver(a), b)
+	}
+
+	if mapping := g.mappingOf(sourceExpr, config); mapping != nil && mapping.Enum == nil && g.helpers != nil {
+		return fmt.Sprintf("%s(%s, %s)", g.equalFunc(mapping), a, b)
+	}
+
+	if g.comparable(sourceExpr, config) {
+		return fmt.Sprintf("%s == %s", a, b)
+	}
+
+	switch t := sourceExpr.(type) {
+	case *ast.SelectorExpr:
+		if g.isTime(t, config) {
+			return fmt.Sprintf("%s.Equal(%s)", receiver(a), b)
+		}
+	case *ast.StarExpr:
+		return fmt.Sprintf(`func(a, b %s) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		return %s
+	}(%s, %s)`, g.targetType(t, config), g.equal("*a", "*b", t.X, config), a, b)
+	case *ast.ArrayType:
+		return fmt.Sprintf(`func(a, b %s) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if %s {
+				return false
+			}
+		}
+		return true
+	}(%s, %s)`, g.targetType(t, config), g.differs("a[i]", "b[i]", t.Elt, config), a, b)
+	case *ast.MapType:
+		return fmt.Sprintf(`func(a, b %s) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for key, item := range a {
+			other, ok := b[key]
+			if !ok || %s {
+				return false
+			}
+		}
+		return true
+	}(%s, %s)`, g.targetType(t, config), g.differs("item", "other", t.Value, config), a, b)
+	}
+
+	return fmt.Sprintf("%s.DeepEqual(%s, %s)", g.imports.alias("reflect", "reflect"), a, b)
+}
+
+// comparable reports whether target values of the target type mapped from sourceExpr are compared with ==:
+// predeclared types but interfaces, enums and named types the reader found comparable (see StructInfo.Comparable),
+// but time.Time
+func (g *Generator) comparable(sourceExpr ast.Expr, config types.MappingConfig) bool {
+	if ident, ok := sourceExpr.(*ast.Ident); ok && predeclared[ident.Name] {
+		return ident.Name != "any" && ident.Name != "error"
+	}
+	if mapping := g.mappingOf(sourceExpr, config); mapping != nil {
+		return mapping.Enum != nil
+	}
+	if g.isTime(sourceExpr, config) {
+		return false
+	}
+	return config.SourceType.Comparable[exprString(sourceExpr)]
+}
+
+// equalFunc returns the helper comparing the targets of a registered nested mapping without Equal field by field
+//
+// Helpers are named after the nested target, eg: equalAccountPost while generating Account, and written at the end
+// of the file
+func (g *Generator) equalFunc(mapping *types.MappingConfig) string {
+	name := "equal" + g.helpers.owner + mapping.TargetType.TypeName
+	if _, ok := g.helpers.names[name]; ok {
+		return name
+	}
+	g.helpers.names[name] = true
+	defer g.helperTypeParams(mapping)()
+
+	var body bytes.Buffer
+	fields, paths := g.mappedFields(*mapping)
+	for i, targetField := range fields {
+		sourceField, sourceConfig, ok := g.findSourceField(targetField, *mapping)
+		if !ok {
+			continue
+		}
+		sourceExpr := comparedType(mirroredField(sourceField, targetField, *mapping))
+		fmt.Fprintf(&body, "\tif %s {\n", g.differs("a."+paths[i], "b."+paths[i], sourceExpr, sourceConfig))
+		body.WriteString("\t\treturn false\n")
+		body.WriteString("\t}\n")
+	}
+
+	targetType := g.targetRef(mapping, *mapping)
+	buf := g.helpers.buf
+	fmt.Fprintf(buf, "// %s reports whether two %s hold the same mapped values, for Equal and Diff\n", name, mapping.TargetType.TypeName)
+	fmt.Fprintf(buf, "func %s%s(a, b %s) bool {\n", name, g.typeParamsDecl(*mapping), targetType)
+	buf.Write(body.Bytes())
+	buf.WriteString("\treturn true\n")
+	buf.WriteString("}\n\n")
+	return name
+}
+
+// compareMapping returns the registered mapping with Equal and Diff a source type (or pointer to it) is converted with
+func (g *Generator) compareMapping(sourceExpr ast.Expr, config types.MappingConfig) (*types.MappingConfig, bool) {
+	star, isPtr := sourceExpr.(*ast.StarExpr)
+	if isPtr {
+		sourceExpr = star.X
+	}
+	if mapping := g.mappingOf(sourceExpr, config); mapping != nil && mapping.Compare {
+		return mapping, isPtr
+	}
+	return nil, false
+}
+
+// comparedType returns the source type of a field, named basic types (eg: type Status string) as their underlying type
+func comparedType(field types.FieldInfo) ast.Expr {
+	if predeclared[field.Underlying] {
+		return ast.NewIdent(field.Underlying)
+	}
+	return parseType(field.Type)
+}
+
+// isTime reports whether a source type is time.Time
+func (g *Generator) isTime(sourceExpr ast.Expr, config types.MappingConfig) bool {
+	if pkg, name, ok := namedType(sourceExpr); ok && name == "Time" {
+		pkgPath, ok := g.resolvePackage(pkg, config)
+		return ok && pkgPath == "time"
+	}
+	return false
+}
+
+// receiver parenthesizes a dereferenced value so methods can be called on it
+func receiver(value string) string {
+	if strings.HasPrefix(value, "*") {
+		return "(" + value + ")"
+	}
+	return value
+}
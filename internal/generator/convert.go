@@ -0,0 +1,224 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// numericBits is the size of every predeclared numeric type, int and uint are taken as 64 bits
+var numericBits = map[string]int{
+	"int": 64, "int8": 8, "int16": 16, "int32": 32, "int64": 64, "rune": 32,
+	"uint": 64, "uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64, "uintptr": 64, "byte": 8,
+	"float32": 32, "float64": 64,
+}
+
+func isUnsigned(kind string) bool {
+	return strings.HasPrefix(kind, "uint") || kind == "byte"
+}
+
+func isFloat(kind string) bool {
+	return strings.HasPrefix(kind, "float")
+}
+
+// conversionPaths returns the source field paths converted to another basic type, sorted
+func conversionPaths(config types.MappingConfig) []string {
+	paths := make([]string, 0, len(config.Conversions))
+	for path := range config.Conversions {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// conversionTarget renders the type of a converted target field
+func (g *Generator) conversionTarget(conversion types.Conversion, tf types.FieldInfo, config types.MappingConfig) string {
+	if config.ExistingTarget {
+		return g.existingTargetType(tf.Type, config)
+	}
+	return conversion.TargetKind
+}
+
+// conversionFails reports whether a converter of a conversion can report false, toTarget for the one converting
+// from the source
+func conversionFails(conversion types.Conversion, toTarget bool) bool {
+	if conversion.Time != types.TimeKeep {
+		return !toTarget && (conversion.Time == types.TimeRFC3339 || conversion.Time == types.DurationMillis)
+	}
+	if toTarget {
+		return !lossless(conversion.SourceKind, conversion.TargetKind)
+	}
+	return !lossless(conversion.TargetKind, conversion.SourceKind)
+}
+
+// conversionExpr converts value with a generated converter returning typ, handling values it can't represent
+// according to the conversion's policy
+func (g *Generator) conversionExpr(conversion types.Conversion, fn, value, typ, kind string) string {
+	if conversion.Policy != types.ConvertPanic || !conversionFails(conversion, fn == conversion.FromFunc) {
+		return g.checkedCall(fn, value, typ, "")
+	}
+	return g.checkedCall(fn, value, typ, fmt.Sprintf("%s: can't convert %%v to %s", conversion.Field, kind))
+}
+
+// checkedCall calls a converter returning typ and whether it could convert value, which panics with message
+// (formatted with value) if it couldn't and message isn't empty
+func (g *Generator) checkedCall(fn, value, typ, message string) string {
+	if message == "" {
+		return fmt.Sprintf(`func() %s {
+		v, _ := %s(%s)
+		return v
+	}()`, typ, fn, value)
+	}
+
+	return fmt.Sprintf(`func() %s {
+		v, ok := %s(%s)
+		if !ok {
+			panic(%s.Sprintf(%q, %s))
+		}
+		return v
+	}()`, typ, fn, value, g.imports.alias("fmt", "fmt"), message, value)
+}
+
+// generateConversions writes the converters of every Convert of a mapping, which report whether the value fits
+// the other type
+func (g *Generator) generateConversions(config types.MappingConfig) {
+	for _, path := range conversionPaths(config) {
+		conversion := config.Conversions[path]
+		tf, sf, sourceConfig, ok := g.enumMapField(path, config)
+		if !ok {
+			continue
+		}
+		sourceType := g.sourceTypeName(sf.Type, sourceConfig)
+		targetType := g.conversionTarget(conversion, tf, config)
+
+		var fromBody, toBody, fromFailure, toFailure string
+		if conversion.Time != types.TimeKeep {
+			fromBody, toBody = g.timeBodies(conversion, sourceType, targetType)
+			fromFailure, toFailure = timeFailure(conversion, true), timeFailure(conversion, false)
+		} else {
+			fromBody = g.conversionBody(conversion.SourceKind, conversion.TargetKind, sourceType, targetType)
+			toBody = g.conversionBody(conversion.TargetKind, conversion.SourceKind, targetType, sourceType)
+			fromFailure = failure(conversion.SourceKind, conversion.TargetKind)
+			toFailure = failure(conversion.TargetKind, conversion.SourceKind)
+		}
+
+		fmt.Fprintf(g.buf, "// %s converts %s to %s, %s\n", conversion.FromFunc, conversion.Field, conversion.TargetKind, fromFailure)
+		fmt.Fprintf(g.buf, "func %s(v %s) (%s, bool) {\n", conversion.FromFunc, sourceType, targetType)
+		fmt.Fprintf(g.buf, "\t%s\n", fromBody)
+		g.buf.WriteString("}\n\n")
+
+		fmt.Fprintf(g.buf, "// %s converts %s back to %s, %s\n", conversion.ToFunc, conversion.Field, conversion.SourceKind, toFailure)
+		fmt.Fprintf(g.buf, "func %s(v %s) (%s, bool) {\n", conversion.ToFunc, targetType, sourceType)
+		fmt.Fprintf(g.buf, "\t%s\n", toBody)
+		g.buf.WriteString("}\n\n")
+	}
+}
+
+// failure describes when a converter from one basic type to another reports false
+func failure(from, to string) string {
+	switch {
+	case lossless(from, to):
+		return "every value fits"
+	case from == "string":
+		return "false if the value isn't a valid " + to
+	case isFloat(from) && !isFloat(to):
+		return "false if the value has a fraction or doesn't fit"
+	}
+	return "false if the value doesn't fit"
+}
+
+// lossless reports whether every value of a basic type fits another, integers converted to floats only lose precision
+func lossless(from, to string) bool {
+	switch {
+	case to == "string":
+		return true
+	case from == "string", isFloat(from) && !isFloat(to):
+		return false
+	case isFloat(to) && !isFloat(from):
+		return true
+	case isUnsigned(from) && !isUnsigned(to):
+		return numericBits[to] > numericBits[from]
+	case isUnsigned(from) == isUnsigned(to):
+		return numericBits[to] >= numericBits[from]
+	}
+	return false
+}
+
+// floatRange returns the check that a float v is within the range of an integer type, with bounds floats hold exactly
+func floatRange(to string) string {
+	bits := numericBits[to]
+	if isUnsigned(to) {
+		return fmt.Sprintf("v >= 0 && v < 1<<%d", bits)
+	}
+	return fmt.Sprintf("v >= -1<<%d && v < 1<<%d", bits-1, bits-1)
+}
+
+// convertTo converts value of type have to typ, if they differ
+func convertTo(typ, have, value string) string {
+	if typ == have {
+		return value
+	}
+	return typ + "(" + value + ")"
+}
+
+// conversionBody returns the statements converting v from one basic type to another, returning the result and
+// whether it fits
+//
+// Strings are parsed and formatted with strconv, numbers are converted and converted back to check they didn't change
+func (g *Generator) conversionBody(from, to, fromType, toType string) string {
+	switch {
+	case from == "string":
+		strconv := g.imports.alias("strconv", "strconv")
+		bits := numericBits[to]
+		if to == "int" || to == "uint" {
+			bits = 0
+		}
+		parse, parsed := fmt.Sprintf("%s.ParseInt(%s, 10, %d)", strconv, convertTo("string", fromType, "v"), bits), "int64"
+		if isUnsigned(to) {
+			parse, parsed = fmt.Sprintf("%s.ParseUint(%s, 10, %d)", strconv, convertTo("string", fromType, "v"), bits), "uint64"
+		}
+		if isFloat(to) {
+			parse, parsed = fmt.Sprintf("%s.ParseFloat(%s, %d)", strconv, convertTo("string", fromType, "v"), bits), "float64"
+		}
+		return fmt.Sprintf(`r, err := %s
+	if err != nil {
+		return 0, false
+	}
+	return %s, true`, parse, convertTo(toType, parsed, "r"))
+
+	case to == "string":
+		strconv := g.imports.alias("strconv", "strconv")
+		format := fmt.Sprintf("%s.FormatInt(%s, 10)", strconv, convertTo("int64", fromType, "v"))
+		if isUnsigned(from) {
+			format = fmt.Sprintf("%s.FormatUint(%s, 10)", strconv, convertTo("uint64", fromType, "v"))
+		}
+		if isFloat(from) {
+			format = fmt.Sprintf("%s.FormatFloat(%s, 'g', -1, %d)", strconv, convertTo("float64", fromType, "v"), numericBits[from])
+		}
+		return fmt.Sprintf("return %s, true", convertTo(toType, "string", format))
+
+	case lossless(from, to):
+		return fmt.Sprintf("return %s(v), true", toType)
+
+	case isFloat(from) && isFloat(to):
+		// values out of range become infinite
+		isInf := g.imports.alias("math", "math") + ".IsInf"
+		return fmt.Sprintf(`r := %s(v)
+	return r, !%s(%s, 0) || %s(%s, 0)`, toType, isInf, convertTo("float64", toType, "r"), isInf, convertTo("float64", fromType, "v"))
+	}
+
+	check := fmt.Sprintf("%s(r) == v", fromType)
+	switch {
+	case isFloat(from):
+		// out of range floats convert to an implementation-defined value, which may convert back to v
+		check += " && " + floatRange(to)
+	case isUnsigned(to) && !isUnsigned(from):
+		check += " && v >= 0"
+	case isUnsigned(from) && !isUnsigned(to):
+		check += " && r >= 0"
+	}
+	return fmt.Sprintf(`r := %s(v)
+	return r, %s`, toType, check)
+}
@@ -0,0 +1,60 @@
+package generator
+
+import "testing"
+
+func TestLossless(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		// signed to signed
+		{"int8", "int16", true},
+		{"int32", "int64", true},
+		{"int64", "int", true},
+		{"int", "int64", true},
+		{"int16", "int8", false},
+		{"int64", "int32", false},
+		{"rune", "int32", true},
+
+		// unsigned to unsigned
+		{"uint8", "uint16", true},
+		{"byte", "uint8", true},
+		{"uint", "uint64", true},
+		{"uint64", "uintptr", true},
+		{"uint32", "uint16", false},
+		{"uint64", "uint32", false},
+
+		// unsigned to signed needs a wider type
+		{"uint8", "int16", true},
+		{"uint32", "int64", true},
+		{"uint8", "int8", false},
+		{"uint32", "int32", false},
+		{"uint64", "int64", false},
+		{"uint", "int", false},
+
+		// signed to unsigned loses negative values
+		{"int8", "uint8", false},
+		{"int8", "uint64", false},
+		{"int", "uint", false},
+
+		// floats
+		{"float32", "float64", true},
+		{"float64", "float32", false},
+		{"float32", "int64", false},
+		{"float64", "uint8", false},
+		{"int64", "float32", true}, // only precision is lost
+		{"uint64", "float64", true},
+
+		// strings
+		{"int8", "string", true},
+		{"float64", "string", true},
+		{"string", "int64", false},
+		{"string", "float64", false},
+	}
+
+	for _, tt := range tests {
+		if got := lossless(tt.from, tt.to); got != tt.want {
+			t.Errorf("lossless(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// generateEnum writes a named basic type mirroring the source's, its constants and the converters between them
+func (g *Generator) generateEnum(config types.MappingConfig) {
+	enum := config.Enum
+	targetType := config.TargetType.TypeName
+	sourceRef := g.sourceRef(config)
+
+	doc := config.SourceType.Doc
+	if config.MirrorComments {
+		doc = joinDoc(doc, "Mirrors "+sourceRef)
+	}
+	g.writeComment("", doc)
+	fmt.Fprintf(g.buf, "type %s %s\n\n", targetType, enum.Underlying)
+
+	g.buf.WriteString("const (\n")
+	for _, value := range enum.Values {
+		doc := value.Doc
+		if value.Name == "" {
+			doc = fmt.Sprintf("%s is what external values without a constant convert to", value.TargetName)
+		}
+		g.writeComment("\t", doc)
+		if value.Comment != "" {
+			fmt.Fprintf(g.buf, "\t%s %s = %s // %s\n", value.TargetName, targetType, value.Value, strings.Join(strings.Fields(value.Comment), " "))
+		} else {
+			fmt.Fprintf(g.buf, "\t%s %s = %s\n", value.TargetName, targetType, value.Value)
+		}
+	}
+	g.buf.WriteString(")\n\n")
+
+	// constants sharing a value convert to the first of them
+	var cases []types.EnumValue
+	seen := make(map[string]bool)
+	fallback := g.zeroValue(enum.Underlying, "")
+	for _, value := range enum.Values {
+		if value.Name != "" && value.TargetName == enum.Unknown {
+			fallback = g.qualifySource("", value.Name, config)
+		}
+		if value.Name == "" || seen[value.Value] {
+			continue
+		}
+		seen[value.Value] = true
+		cases = append(cases, value)
+	}
+
+	fmt.Fprintf(g.buf, "// %s maps from an external value to a local, values without a constant convert to %s\n", config.FromFuncName, enum.Unknown)
+	fmt.Fprintf(g.buf, "func %s(v %s) %s {\n", config.FromFuncName, sourceRef, targetType)
+	g.buf.WriteString("\tswitch v {\n")
+	for _, value := range cases {
+		fmt.Fprintf(g.buf, "\tcase %s:\n", g.qualifySource("", value.Name, config))
+		fmt.Fprintf(g.buf, "\t\treturn %s\n", value.TargetName)
+	}
+	g.buf.WriteString("\t}\n")
+	fmt.Fprintf(g.buf, "\treturn %s\n", enum.Unknown)
+	g.buf.WriteString("}\n\n")
+
+	fmt.Fprintf(g.buf, "// %s maps from a local value back to an external, values without a constant convert to %s\n", config.ToFuncName, fallback)
+	fmt.Fprintf(g.buf, "func %s(v %s) %s {\n", config.ToFuncName, targetType, sourceRef)
+	g.buf.WriteString("\tswitch v {\n")
+	for _, value := range cases {
+		fmt.Fprintf(g.buf, "\tcase %s:\n", value.TargetName)
+		fmt.Fprintf(g.buf, "\t\treturn %s\n", g.qualifySource("", value.Name, config))
+	}
+	g.buf.WriteString("\t}\n")
+	fmt.Fprintf(g.buf, "\treturn %s\n", fallback)
+	g.buf.WriteString("}\n\n")
+}
@@ -0,0 +1,309 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// enumMapPaths returns the source field paths converted with a table, sorted
+func enumMapPaths(config types.MappingConfig) []string {
+	paths := make([]string, 0, len(config.EnumMaps))
+	for path := range config.EnumMaps {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// enumMapField returns the target and source field of a source field path converted with a table,
+// along with a config whose source is the struct declaring it
+func (g *Generator) enumMapField(path string, config types.MappingConfig) (types.FieldInfo, types.FieldInfo, types.MappingConfig, bool) {
+	tf := g.findTargetField(path, config)
+	if tf == nil {
+		return types.FieldInfo{}, types.FieldInfo{}, config, false
+	}
+	sf, sourceConfig, ok := g.findSourceField(*tf, config)
+	return *tf, sf, sourceConfig, ok
+}
+
+// typeRef renders a named type as seen from the generated package
+func (g *Generator) typeRef(t types.TypeRef, config types.MappingConfig) string {
+	if t.PackagePath == config.OutputPath {
+		return t.Name
+	}
+	return g.imports.alias(t.PackagePath, t.PackageName) + "." + t.Name
+}
+
+// valueRef renders a constant (or literal) as seen from the generated package
+func (g *Generator) valueRef(v types.ValueRef, config types.MappingConfig) string {
+	if v.Name == "" {
+		return v.Literal
+	}
+	return g.typeRef(types.TypeRef{PackagePath: v.PackagePath, PackageName: v.PackageName, Name: v.Name}, config)
+}
+
+// enumMapExpr converts value with a table converter returning typ, handling values missing from the table
+// according to the table's policy
+func (g *Generator) enumMapExpr(enumMap types.EnumMap, fn, value, typ string) string {
+	if enumMap.Policy != types.EnumPanic {
+		return g.checkedCall(fn, value, typ, "")
+	}
+	return g.checkedCall(fn, value, typ, enumMap.Field+": no mapping for %#v")
+}
+
+// generateEnumMaps writes the converters of every table of a mapping, which report whether the value was in the table
+//
+// Only the table's values are known, the defaults (zero values included) aren't unless the table lists them
+func (g *Generator) generateEnumMaps(config types.MappingConfig) {
+	for _, path := range enumMapPaths(config) {
+		enumMap := config.EnumMaps[path]
+		_, sf, sourceConfig, ok := g.enumMapField(path, config)
+		if !ok {
+			continue
+		}
+		sourceType := g.sourceTypeName(sf.Type, sourceConfig)
+		targetType := g.typeRef(enumMap.Target, config)
+
+		fmt.Fprintf(g.buf, "// %s converts %s with its MapEnum table, values missing from it convert to %s and false\n",
+			enumMap.FromFunc, enumMap.Field, g.valueRef(enumMap.Default, config))
+		fmt.Fprintf(g.buf, "func %s(v %s) (%s, bool) {\n", enumMap.FromFunc, sourceType, targetType)
+		g.buf.WriteString("\tswitch v {\n")
+		seen := make(map[string]bool)
+		for _, pair := range enumMap.Values {
+			if seen[pair.Source.Literal] {
+				continue
+			}
+			seen[pair.Source.Literal] = true
+			fmt.Fprintf(g.buf, "\tcase %s:\n", g.valueRef(pair.Source, config))
+			fmt.Fprintf(g.buf, "\t\treturn %s, true\n", g.valueRef(pair.Target, config))
+		}
+		g.buf.WriteString("\t}\n")
+		fmt.Fprintf(g.buf, "\treturn %s, false\n", g.valueRef(enumMap.Default, config))
+		g.buf.WriteString("}\n\n")
+
+		// target values mapped from more than one source value convert back to the first of them
+		fmt.Fprintf(g.buf, "// %s converts %s back with its MapEnum table, values missing from it convert to %s and false\n",
+			enumMap.ToFunc, enumMap.Field, g.valueRef(enumMap.SourceDefault, config))
+		fmt.Fprintf(g.buf, "func %s(v %s) (%s, bool) {\n", enumMap.ToFunc, targetType, sourceType)
+		g.buf.WriteString("\tswitch v {\n")
+		seen = make(map[string]bool)
+		for _, pair := range enumMap.Values {
+			if seen[pair.Target.Literal] {
+				continue
+			}
+			seen[pair.Target.Literal] = true
+			fmt.Fprintf(g.buf, "\tcase %s:\n", g.valueRef(pair.Target, config))
+			fmt.Fprintf(g.buf, "\t\treturn %s, true\n", g.valueRef(pair.Source, config))
+		}
+		g.buf.WriteString("\t}\n")
+		fmt.Fprintf(g.buf, "\treturn %s, false\n", g.valueRef(enumMap.SourceDefault, config))
+		g.buf.WriteString("}\n\n")
+	}
+}
+
+// checkedField is a field FromE and ToE check with its converters before converting, see generateCheckedMethods
+type checkedField struct {
+	path             string
+	fromFunc, toFunc string // empty for directions that can't fail
+	fromMsg, toMsg   string // error messages, formatted with the value
+}
+
+// checkedFields returns the fields converted with EnumError tables and ConvertError conversions
+func checkedFields(config types.MappingConfig) []checkedField {
+	var fields []checkedField
+	for _, path := range enumMapPaths(config) {
+		if enumMap := config.EnumMaps[path]; enumMap.Policy == types.EnumError {
+			msg := enumMap.Field + ": no mapping for %#v"
+			fields = append(fields, checkedField{path, enumMap.FromFunc, enumMap.ToFunc, msg, msg})
+		}
+	}
+	for _, path := range conversionPaths(config) {
+		conversion := config.Conversions[path]
+		if conversion.Policy != types.ConvertError {
+			continue
+		}
+		field := checkedField{path: path,
+			fromMsg: conversion.Field + ": can't convert %v to " + conversion.TargetKind,
+			toMsg:   conversion.Field + ": can't convert %v to " + conversion.SourceKind,
+		}
+		// converters that can't fail aren't checked
+		if conversionFails(conversion, true) {
+			field.fromFunc = conversion.FromFunc
+		}
+		if conversionFails(conversion, false) {
+			field.toFunc = conversion.ToFunc
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// generateCheckedMethods writes FromE and ToE, which fail on the values From and To can't represent
+// (values missing from tables with EnumError, out of range of conversions with ConvertError) before
+// converting with From and To
+func (g *Generator) generateCheckedMethods(config types.MappingConfig) {
+	targetType := config.TargetType.TypeName
+	sourceType := config.SourceType.TypeName
+	sourceRef := g.sourceRef(config)
+	targetRef := g.targetRef(&config, config)
+	fields := checkedFields(config)
+
+	if config.FromFuncName != "" {
+		name := config.FromFuncName + "E"
+		fmt.Fprintf(g.buf, "// %s maps from an external struct to a local like %s, %s\n", name, config.FromFuncName, fromFailure(config, fields))
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: local%s, err := %s(&external%s)\n", targetType, name, sourceType)
+		fmt.Fprintf(g.buf, "func %s%s(src *%s) (*%s, error) {\n", name, g.typeParamsDecl(config), sourceRef, targetRef)
+	} else {
+		fmt.Fprintf(g.buf, "// FromE maps from an external struct to a local like From, %s\n", fromFailure(config, fields))
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: local%s, err := (&%s{}).FromE(&external%s)\n", targetType, targetType, sourceType)
+		fmt.Fprintf(g.buf, "func (t *%s) FromE(src *%s) (*%s, error) {\n", targetRef, sourceRef, targetRef)
+	}
+	g.buf.WriteString("\tif src == nil {\n")
+	g.buf.WriteString("\t\treturn nil, nil\n")
+	g.buf.WriteString("\t}\n")
+	for _, field := range fields {
+		if field.fromFunc == "" {
+			continue
+		}
+		value := "src." + field.path
+
+		// flattened fields are only checked if they're reachable
+		pointers := g.pointerPaths(field.path, config)
+		if len(pointers) > 0 {
+			fmt.Fprintf(g.buf, "\tif src.%s != nil {\n", strings.Join(pointers, " != nil && src."))
+		}
+		fmt.Fprintf(g.buf, "\tif _, ok := %s(%s); !ok {\n", field.fromFunc, value)
+		fmt.Fprintf(g.buf, "\t\treturn nil, %s(%q, %s)\n", g.imports.alias("fmt", "fmt")+".Errorf", field.fromMsg, value)
+		g.buf.WriteString("\t}\n")
+		if len(pointers) > 0 {
+			g.buf.WriteString("\t}\n")
+		}
+	}
+	if config.ValidateFromE {
+		fmt.Fprintf(g.buf, "\tresult := %s\n", g.fromCall(&config, "src", config))
+		fmt.Fprintf(g.buf, "\tif err := %s; err != nil {\n", g.validateCall(&config, "result", true))
+		g.buf.WriteString("\t\treturn nil, err\n")
+		g.buf.WriteString("\t}\n")
+		g.buf.WriteString("\treturn result, nil\n")
+	} else {
+		fmt.Fprintf(g.buf, "\treturn %s, nil\n", g.fromCall(&config, "src", config))
+	}
+	g.buf.WriteString("}\n\n")
+
+	if config.OmitPolicy == types.OmitNoTo {
+		return
+	}
+	withBase := config.OmitPolicy == types.OmitFromBase
+
+	switch {
+	case config.ToFuncName != "" && withBase:
+		name := config.ToFuncName + "E"
+		fmt.Fprintf(g.buf, "// %s maps from a local struct back to an external like %s, failing on the values it can't convert\n", name, config.ToFuncName)
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: external%s, err = %s(&local%s, external%s)\n", sourceType, name, targetType, sourceType)
+		fmt.Fprintf(g.buf, "func %s%s(t *%s, base %s) (%s, error) {\n", name, g.typeParamsDecl(config), targetRef, sourceRef, sourceRef)
+	case config.ToFuncName != "":
+		name := config.ToFuncName + "E"
+		fmt.Fprintf(g.buf, "// %s maps from a local struct back to an external like %s, failing on the values it can't convert\n", name, config.ToFuncName)
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: external%s, err := %s(&local%s)\n", sourceType, name, targetType)
+		fmt.Fprintf(g.buf, "func %s%s(t *%s) (%s, error) {\n", name, g.typeParamsDecl(config), targetRef, sourceRef)
+	case withBase:
+		g.buf.WriteString("// ToWithE maps from a local struct back to an external like ToWith, failing on the values it can't convert\n")
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: external%s, err = %s.ToWithE(external%s)\n", sourceType, targetType, sourceType)
+		fmt.Fprintf(g.buf, "func (t *%s) ToWithE(base %s) (%s, error) {\n", targetRef, sourceRef, sourceRef)
+	default:
+		g.buf.WriteString("// ToE maps from a local struct back to an external like To, failing on the values it can't convert\n")
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: external%s, err := %s.ToE()\n", sourceType, targetType)
+		fmt.Fprintf(g.buf, "func (t *%s) ToE() (%s, error) {\n", targetRef, sourceRef)
+	}
+	for _, field := range fields {
+		tf, _, _, ok := g.enumMapField(field.path, config)
+		if !ok || field.toFunc == "" {
+			continue
+		}
+		value := "t." + tf.Name
+		fmt.Fprintf(g.buf, "\tif _, ok := %s(%s); !ok {\n", field.toFunc, value)
+		fmt.Fprintf(g.buf, "\t\treturn %s{}, %s(%q, %s)\n", sourceRef, g.imports.alias("fmt", "fmt")+".Errorf", field.toMsg, value)
+		g.buf.WriteString("\t}\n")
+	}
+
+	switch {
+	case config.ToFuncName != "" && withBase:
+		fmt.Fprintf(g.buf, "\treturn %s(t, base), nil\n", config.ToFuncName)
+	case config.ToFuncName != "":
+		fmt.Fprintf(g.buf, "\treturn %s(t), nil\n", config.ToFuncName)
+	case withBase:
+		g.buf.WriteString("\treturn t.ToWith(base), nil\n")
+	default:
+		g.buf.WriteString("\treturn t.To(), nil\n")
+	}
+	g.buf.WriteString("}\n\n")
+}
+
+// GenerateEnumTests generates a test per MapEnum table checking that every constant of its source and target types
+// is in it (or one of its defaults). Returns "" if there's nothing to check
+//
+// The imports required by the generated code are available from Imports afterwards
+func (g *Generator) GenerateEnumTests(configs []types.MappingConfig) string {
+	g.buf = &bytes.Buffer{}
+	g.typeParams = make(map[string]bool)
+	if len(configs) > 0 {
+		g.imports = newImportPlanner(g.reservedNames(configs[0])...)
+	}
+
+	for _, config := range configs {
+		for _, path := range enumMapPaths(config) {
+			g.generateEnumTest(config, path)
+		}
+	}
+
+	return g.buf.String()
+}
+
+func (g *Generator) generateEnumTest(config types.MappingConfig, path string) {
+	enumMap := config.EnumMaps[path]
+	_, sf, sourceConfig, ok := g.enumMapField(path, config)
+	if !ok {
+		return
+	}
+
+	var sourceConsts, targetConsts []string
+	for _, c := range enumMap.SourceConsts {
+		sourceConsts = append(sourceConsts, g.valueRef(c, config))
+	}
+	for _, c := range enumMap.TargetConsts {
+		targetConsts = append(targetConsts, g.valueRef(c, config))
+	}
+	if len(sourceConsts) == 0 && len(targetConsts) == 0 {
+		return
+	}
+
+	testing := g.imports.alias("testing", "testing")
+	name := "Test" + config.TargetType.TypeName + strings.ReplaceAll(path, ".", "") + "Enum"
+	fmt.Fprintf(g.buf, "// %s checks that every constant converted by the MapEnum table of %s is in it\n", name, enumMap.Field)
+	fmt.Fprintf(g.buf, "func %s(t *%s.T) {\n", name, testing)
+	if len(sourceConsts) > 0 {
+		fmt.Fprintf(g.buf, "\tfor _, v := range []%s{%s} {\n", g.sourceTypeName(sf.Type, sourceConfig), strings.Join(sourceConsts, ", "))
+		fmt.Fprintf(g.buf, "\t\tif _, ok := %s(v); !ok {\n", enumMap.FromFunc)
+		fmt.Fprintf(g.buf, "\t\t\tt.Errorf(\"%%v has no mapping\", v)\n")
+		g.buf.WriteString("\t\t}\n")
+		g.buf.WriteString("\t}\n")
+	}
+	if len(targetConsts) > 0 {
+		fmt.Fprintf(g.buf, "\tfor _, v := range []%s{%s} {\n", g.typeRef(enumMap.Target, config), strings.Join(targetConsts, ", "))
+		fmt.Fprintf(g.buf, "\t\tif _, ok := %s(v); !ok {\n", enumMap.ToFunc)
+		fmt.Fprintf(g.buf, "\t\t\tt.Errorf(\"%%v has no mapping back\", v)\n")
+		g.buf.WriteString("\t\t}\n")
+		g.buf.WriteString("\t}\n")
+	}
+	g.buf.WriteString("}\n\n")
+}
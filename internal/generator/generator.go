@@ -2,7 +2,9 @@ package generator
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"go/ast"
 	"go/format"
 	"strings"
 
@@ -11,8 +13,12 @@ import (
 
 type Generator struct {
 	buf              *bytes.Buffer
-	generatedStructs map[string]bool   // track structs that have already been generated
-	nestedStructs    []types.FieldInfo // track nested that need generation
+	generatedStructs map[string]bool                 // track structs that have already been generated
+	nestedStructs    []types.FieldInfo               // track nested that need generation
+	mappings         map[string]*types.MappingConfig // all registered mappings, keyed by source type
+	imports          *importPlanner                  // imports of the file being generated
+	typeParams       map[string]bool                 // type parameters of the generic source being generated
+	helpers          *helperFuncs                    // helpers of the file being generated, see cloneFunc and equalFunc
 }
 
 func New() *Generator {
@@ -21,21 +27,28 @@ func New() *Generator {
 	}
 }
 
+// SetMappings registers every mapping being generated so that nested source types
+// can be resolved to their generated targets
+func (g *Generator) SetMappings(configs []types.MappingConfig) {
+	g.mappings = make(map[string]*types.MappingConfig, len(configs))
+	for i := range configs {
+		source := configs[i].SourceType
+		g.mappings[canonicalType(sourceTypeExpr(source), source.PackagePath, source.Imports)] = &configs[i]
+	}
+}
+
 func (g *Generator) Generate(config types.MappingConfig) (string, error) {
-	g.buf = &bytes.Buffer{}
-	g.generatedStructs = make(map[string]bool)
-	g.nestedStructs = []types.FieldInfo{}
+	// generate body first so the imports it needs are known
+	code, err := g.GenerateStructAndMethods(config)
+	if err != nil {
+		return "", err
+	}
 
 	// pkg & imports
-	g.writePackage(config.TargetType.PackageName)
-	g.writeImports(config)
-
-	// generate definition
-	g.generateStructDef(config)
-
-	// generate methods
-	g.generateFromMethod(config)
-	g.generateToMethod(config)
+	g.buf = &bytes.Buffer{}
+	g.writePackage(config.OutputPackage)
+	g.writeImports()
+	g.buf.WriteString(code)
 
 	// format
 	formatted, err := format.Source(g.buf.Bytes())
@@ -47,143 +60,280 @@ func (g *Generator) Generate(config types.MappingConfig) (string, error) {
 }
 
 // GenerateStructAndMethods generates only the struct and methods without package/imports
+//
+// The imports required by the generated code are available from Imports afterwards
 func (g *Generator) GenerateStructAndMethods(config types.MappingConfig) (string, error) {
 	g.buf = &bytes.Buffer{}
 	g.generatedStructs = make(map[string]bool)
 	g.nestedStructs = []types.FieldInfo{}
+	g.imports = newImportPlanner(g.reservedNames(config)...)
+	g.typeParams = make(map[string]bool)
+	for _, param := range config.SourceType.TypeParams {
+		g.typeParams[param.Name] = true
+	}
+
+	if config.Enum != nil {
+		g.generateEnum(config)
+		return g.buf.String(), nil
+	}
 
-	// Generate struct definition
-	g.generateStructDef(config)
+	g.helpers = newHelperFuncs(config)
+	defer func() { g.helpers = nil }()
+
+	if config.ExistingTarget {
+		// only converters are generated for existing targets, their fields must line up
+		if err := g.checkTargetTypes(config); err != nil {
+			return "", err
+		}
+	} else {
+		// Generate struct definition
+		g.generateStructDef(config)
+		g.generateAccessors(config)
+	}
 
 	// Generate From method
 	g.generateFromMethod(config)
 
 	// Generate To method
-	g.generateToMethod(config)
+	if config.OmitPolicy != types.OmitNoTo {
+		if err := g.checkNestedTo(config, "To"); err != nil {
+			return "", err
+		}
+		g.generateToMethod(config)
+	}
+
+	// Generate FromE and ToE methods
+	if config.Checked {
+		g.generateCheckedMethods(config)
+	}
+
+	// Generate ApplyTo method
+	if config.ApplyTo {
+		if err := g.checkNestedTo(config, "ApplyTo"); err != nil {
+			return "", err
+		}
+		g.generateApplyMethod(config)
+	}
+
+	// Generate bulk converters
+	g.generateSliceFuncs(config)
+
+	// Generate Clone method
+	if config.Clone {
+		g.generateCloneMethod(config)
+	}
+
+	// Generate Equal and Diff methods
+	if config.Compare {
+		g.generateCompareMethods(config)
+	}
+
+	// Generate Validate method
+	if config.Validate {
+		g.generateValidateMethod(config)
+	}
+
+	// Generate MapEnum converters
+	g.generateEnumMaps(config)
+	g.generateConversions(config)
+
+	// helpers copying and comparing nested types, used by the methods above
+	g.buf.Write(g.helpers.buf.Bytes())
 
 	return g.buf.String(), nil
 }
 
+// Imports returns the imports used by the last generated file
+func (g *Generator) Imports() []Import {
+	if g.imports == nil {
+		return nil
+	}
+	return g.imports.imports()
+}
+
 func (g *Generator) writePackage(pkgName string) {
 	fmt.Fprintf(g.buf, "package %s\n\n", pkgName)
 }
 
-func (g *Generator) writeImports(config types.MappingConfig) {
-	imports := g.collectImports(config)
+func (g *Generator) writeImports() {
+	imports := g.Imports()
 	if len(imports) == 0 {
 		return
 	}
 
 	g.buf.WriteString("import (\n")
-	for _, imp := range imports {
-		fmt.Fprintf(g.buf, "\t\"%s\"\n", imp)
+	for i, imp := range imports {
+		// separate standard library imports
+		if i > 0 && imports[i-1].IsStd() != imp.IsStd() {
+			g.buf.WriteString("\n")
+		}
+		fmt.Fprintf(g.buf, "\t%s \"%s\"\n", imp.Name, imp.Path)
 	}
 	g.buf.WriteString(")\n\n")
 }
 
-func (g *Generator) collectImports(config types.MappingConfig) []string {
-	importsMap := make(map[string]bool)
-
-	// generate source package import (needed for From(), To() methods)
-	if config.SourceType.PackagePath != "" {
-		importsMap[config.SourceType.PackagePath] = true
-	}
-
-	for _, field := range config.SourceType.Fields {
-		if strings.Contains(field.Type, "time.Time") {
-			importsMap["time"] = true
+// reservedNames returns the identifiers declared by the target package, which imports can't shadow
+func (g *Generator) reservedNames(config types.MappingConfig) []string {
+	names := []string{config.OutputPackage}
+	for _, mapping := range g.mappings {
+		names = append(names, mapping.FromFuncName, mapping.ToFuncName, mapping.ApplyFuncName, mapping.ValidateFuncName)
+		names = append(names, mapping.SliceFuncs.Names()...)
+		if mapping.Checked && mapping.FromFuncName != "" {
+			names = append(names, mapping.FromFuncName+"E", mapping.ToFuncName+"E")
 		}
-	}
-	for _, field := range config.TargetType.Fields {
-		if strings.Contains(field.Type, "time.Time") {
-			importsMap["time"] = true
+		for _, enumMap := range mapping.EnumMaps {
+			names = append(names, enumMap.FromFunc, enumMap.ToFunc)
+		}
+		for _, conversion := range mapping.Conversions {
+			names = append(names, conversion.FromFunc, conversion.ToFunc)
+		}
+		for _, rules := range mapping.Rules {
+			for _, rule := range rules {
+				names = append(names, rule.Var)
+			}
+		}
+		if mapping.Validate {
+			names = append(names, ValidationErrorType, PrefixValidationErrors)
+		}
+		if mapping.Enum != nil {
+			for _, value := range mapping.Enum.Values {
+				names = append(names, value.TargetName)
+			}
+		}
+		if mapping.ExistingTarget {
+			continue
+		}
+		names = append(names, mapping.TargetType.TypeName)
+		for _, group := range mapping.Groups {
+			names = append(names, group.Type.TypeName)
 		}
 	}
-
-	imports := make([]string, 0, len(importsMap))
-	for imp := range importsMap {
-		imports = append(imports, imp)
-	}
-	return imports
+	return names
 }
 
 func (g *Generator) generateStructDef(config types.MappingConfig) {
 	targetTypeName := config.TargetType.TypeName
 
-	fmt.Fprintf(g.buf, "type %s struct {\n", targetTypeName)
+	sourceRef := g.sourceRef(config)
 
-	// generate fields from source
-	for _, sourceField := range config.SourceType.Fields {
-		if config.OmitFields[sourceField.Name] {
-			continue
-		}
-
-		// check for custom mapping
-		targetFieldName := sourceField.Name
-		for srcName, tgtName := range config.FieldMap {
-			if srcName == sourceField.Name {
-				targetFieldName = tgtName
-				break
-			}
-		}
+	doc := config.SourceType.Doc
+	if config.MirrorComments {
+		doc = joinDoc(doc, "Mirrors "+sourceRef)
+	}
+	g.writeComment("", doc)
+	fmt.Fprintf(g.buf, "type %s%s struct {\n", targetTypeName, g.typeParamsDecl(config))
 
-		// clean type name
-		typeStr := g.cleanTypeName(sourceField.Type, config)
-		fmt.Fprintf(g.buf, "\t%s %s\n", targetFieldName, typeStr)
+	for _, targetField := range config.TargetType.Fields {
+		g.generateFieldDef(targetField, config)
 	}
 
 	g.buf.WriteString("}\n\n")
 	g.generatedStructs[targetTypeName] = true
+
+	// structs of grouped fields
+	for _, group := range config.Groups {
+		fmt.Fprintf(g.buf, "// %s groups fields of %s\n", group.Type.TypeName, sourceRef)
+		fmt.Fprintf(g.buf, "type %s struct {\n", group.Type.TypeName)
+		for _, groupField := range group.Type.Fields {
+			g.generateFieldDef(groupField, config)
+		}
+		g.buf.WriteString("}\n\n")
+		g.generatedStructs[group.Type.TypeName] = true
+	}
 }
 
-func (g *Generator) cleanTypeName(typeStr string, config types.MappingConfig) string {
-	sourcePrefix := config.SourceType.PackageName + "."
-	return strings.ReplaceAll(typeStr, sourcePrefix, "")
+// generateFieldDef writes a single field of a generated struct
+func (g *Generator) generateFieldDef(targetField types.FieldInfo, config types.MappingConfig) {
+	// target-only fields
+	if extraField := g.findExtraField(targetField.Name, config); targetField.Source == "" && extraField != nil {
+		fmt.Fprintf(g.buf, "\t%s %s\n", extraField.Name, g.extraFieldType(*extraField))
+		return
+	}
+
+	sourceField, sourceConfig, ok := g.findSourceField(targetField, config)
+	if !ok {
+		// grouped fields
+		fmt.Fprintf(g.buf, "\t%s %s\n", targetField.Name, targetField.Type)
+		return
+	}
+
+	doc := sourceField.Doc
+	if config.MirrorComments {
+		doc = joinDoc(doc, "Mirrors "+g.sourceRef(config)+"."+targetField.Source)
+	}
+	g.writeComment("\t", doc)
+
+	typeStr, _ := g.fieldTargetType(sourceField, targetField, sourceConfig)
+	if sourceField.Comment != "" {
+		fmt.Fprintf(g.buf, "\t%s %s // %s\n", targetField.Name, typeStr, strings.Join(strings.Fields(sourceField.Comment), " "))
+	} else {
+		fmt.Fprintf(g.buf, "\t%s %s\n", targetField.Name, typeStr)
+	}
+}
+
+// extraFieldType renders the type of a target-only field, importing its package if needed
+func (g *Generator) extraFieldType(field types.ExtraField) string {
+	expr := parseType(field.Type)
+	if expr == nil || field.Import == "" {
+		return field.Type
+	}
+
+	return rewriteType(expr, func(pkg, name string) string {
+		if pkg == "" {
+			return name
+		}
+		return g.imports.alias(field.Import, pkg) + "." + name
+	})
 }
 
 func (g *Generator) generateFromMethod(config types.MappingConfig) {
 	targetType := config.TargetType.TypeName
 	sourceType := config.SourceType.TypeName
-	sourcePackage := config.SourceType.PackageName
-
-	g.buf.WriteString("// From maps from an external struct to a local\n")
-	g.buf.WriteString("//\n")
-	fmt.Fprintf(g.buf, "// Usage: local%s := (&%s{}).From(&external%s)\n", targetType, targetType, sourceType)
-	fmt.Fprintf(g.buf, "func (t *%s) From(src *%s.%s) *%s {\n", targetType, sourcePackage, sourceType, targetType)
+	sourceRef := g.sourceRef(config)
+	targetRef := g.targetRef(&config, config)
+
+	if config.FromFuncName != "" {
+		fmt.Fprintf(g.buf, "// %s maps from an external struct to a local\n", config.FromFuncName)
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: local%s := %s(&external%s)\n", targetType, config.FromFuncName, sourceType)
+		fmt.Fprintf(g.buf, "func %s%s(src *%s) *%s {\n", config.FromFuncName, g.typeParamsDecl(config), sourceRef, targetRef)
+	} else {
+		g.buf.WriteString("// From maps from an external struct to a local\n")
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: local%s := (&%s{}).From(&external%s)\n", targetType, targetType, sourceType)
+		fmt.Fprintf(g.buf, "func (t *%s) From(src *%s) *%s {\n", targetRef, sourceRef, targetRef)
+	}
 	g.buf.WriteString("\tif src == nil {\n")
 	g.buf.WriteString("\t\treturn nil\n")
 	g.buf.WriteString("\t}\n\n")
 
-	fmt.Fprintf(g.buf, "\treturn &%s{\n", targetType)
+	fmt.Fprintf(g.buf, "\treturn &%s{\n", targetRef)
 
 	// generate fields from source
-	for _, sourceField := range config.SourceType.Fields {
-		if config.OmitFields[sourceField.Name] {
+	for _, targetField := range config.TargetType.Fields {
+		// target-only fields are left zero unless populated from the source
+		if extraField := g.findExtraField(targetField.Name, config); targetField.Source == "" && extraField != nil {
+			if extraField.FromFunc != "" {
+				fmt.Fprintf(g.buf, "\t\t%s: %s(src),\n", targetField.Name, extraField.FromFunc)
+			}
 			continue
 		}
 
-		// get target field name (derive if not overridden)
-		targetFieldName := sourceField.Name
-		for srcName, tgtName := range config.FieldMap {
-			if srcName == sourceField.Name {
-				targetFieldName = tgtName
-				break
+		// grouped fields are gathered into their struct
+		if group := g.findGroup(targetField.Name, config); targetField.Source == "" && group != nil {
+			fmt.Fprintf(g.buf, "\t\t%s: %s{\n", targetField.Name, group.Type.TypeName)
+			for _, groupField := range group.Type.Fields {
+				if mappingExpr, ok := g.generateFromExpr(groupField, config); ok {
+					fmt.Fprintf(g.buf, "\t\t%s: %s,\n", groupField.Name, mappingExpr)
+				}
 			}
+			g.buf.WriteString("\t\t},\n")
+			continue
 		}
 
-		// create pseudo target field (for type comparison)
-		targetFieldType := g.cleanTypeName(sourceField.Type, config)
-		targetField := types.FieldInfo{
-			Name:      targetFieldName,
-			Type:      targetFieldType,
-			IsPointer: sourceField.IsPointer,
-			IsSlice:   sourceField.IsSlice,
-			IsNested:  sourceField.IsNested,
+		if mappingExpr, ok := g.generateFromExpr(targetField, config); ok {
+			fmt.Fprintf(g.buf, "\t\t%s: %s,\n", targetField.Name, mappingExpr)
 		}
-
-		// generate mapping expression
-		mappingExpr := g.generateFieldMapping(sourceField, targetField, config)
-		fmt.Fprintf(g.buf, "\t\t%s: %s,\n", targetFieldName, mappingExpr)
 	}
 
 	g.buf.WriteString("\t}\n")
@@ -193,228 +343,742 @@ func (g *Generator) generateFromMethod(config types.MappingConfig) {
 func (g *Generator) generateToMethod(config types.MappingConfig) {
 	targetType := config.TargetType.TypeName
 	sourceType := config.SourceType.TypeName
-	sourcePackage := config.SourceType.PackageName
-
-	fmt.Fprintf(g.buf, "// Usage: external%s := %s.To()\n", sourceType, targetType)
+	sourceRef := g.sourceRef(config)
+	targetRef := g.targetRef(&config, config)
+
+	withBase := config.OmitPolicy == types.OmitFromBase
+
+	switch {
+	case config.ToFuncName != "" && withBase:
+		fmt.Fprintf(g.buf, "// %s maps from a local struct back to an external, fields the local struct doesn't carry are copied from base\n", config.ToFuncName)
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: external%s = %s(&local%s, external%s)\n", sourceType, config.ToFuncName, targetType, sourceType)
+		fmt.Fprintf(g.buf, "func %s%s(t *%s, base %s) %s {\n", config.ToFuncName, g.typeParamsDecl(config), targetRef, sourceRef, sourceRef)
+	case config.ToFuncName != "":
+		fmt.Fprintf(g.buf, "// %s maps from a local struct back to an external\n", config.ToFuncName)
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: external%s := %s(&local%s)\n", sourceType, config.ToFuncName, targetType)
+		fmt.Fprintf(g.buf, "func %s%s(t *%s) %s {\n", config.ToFuncName, g.typeParamsDecl(config), targetRef, sourceRef)
+	case withBase:
+		g.buf.WriteString("// ToWith maps from a local struct back to an external, fields the local struct doesn't carry are copied from base\n")
+		g.buf.WriteString("//\n")
+		fmt.Fprintf(g.buf, "// Usage: external%s = %s.ToWith(external%s)\n", sourceType, targetType, sourceType)
+		fmt.Fprintf(g.buf, "func (t *%s) ToWith(base %s) %s {\n", targetRef, sourceRef, sourceRef)
+	default:
+		fmt.Fprintf(g.buf, "// Usage: external%s := %s.To()\n", sourceType, targetType)
+		fmt.Fprintf(g.buf, "func (t *%s) To() %s {\n", targetRef, sourceRef)
+	}
 
-	fmt.Fprintf(g.buf, "func (t *%s) To() %s.%s {\n", targetType, sourcePackage, sourceType)
+	// target-only fields with a To func are applied to the result before returning it
+	var toFuncFields []types.ExtraField
+	for _, field := range config.ExtraFields {
+		if field.ToFunc != "" {
+			toFuncFields = append(toFuncFields, field)
+		}
+	}
 
-	fmt.Fprintf(g.buf, "\treturn %s.%s{\n", sourcePackage, sourceType)
+	// the result is kept in dst when anything is applied to it after the literal
+	var body bytes.Buffer
+	buf := g.buf
+	g.buf = &body
+	zeroed := g.generateToFields(config, config, "")
+	g.buf = buf
+
+	keepDst := len(toFuncFields) > 0 || withBase && len(zeroed) > 0
+	if keepDst {
+		fmt.Fprintf(g.buf, "\tdst := %s{\n", sourceRef)
+	} else {
+		fmt.Fprintf(g.buf, "\treturn %s{\n", sourceRef)
+	}
 
 	// Generate field mappings (reverse of From)
-	for _, sourceField := range config.SourceType.Fields {
-		// If field was omitted in target, we still need to provide a value in source
-		if config.OmitFields[sourceField.Name] {
-			// Use zero value for omitted fields
-			fmt.Fprintf(g.buf, "\t\t%s: %s,\n", sourceField.Name, g.zeroValue(sourceField.Type))
+	g.buf.Write(body.Bytes())
+
+	g.buf.WriteString("\t}\n")
+
+	if withBase {
+		for _, sourcePath := range zeroed {
+			// flattened structs are always rebuilt in dst, but may be nil in base
+			if pointers := g.pointerPaths(sourcePath, config); len(pointers) > 0 {
+				fmt.Fprintf(g.buf, "\tif base.%s != nil {\n", strings.Join(pointers, " != nil && base."))
+				fmt.Fprintf(g.buf, "\t\tdst.%s = base.%s\n", sourcePath, sourcePath)
+				g.buf.WriteString("\t}\n")
+				continue
+			}
+			fmt.Fprintf(g.buf, "\tdst.%s = base.%s\n", sourcePath, sourcePath)
+		}
+	}
+
+	if keepDst {
+		for _, field := range toFuncFields {
+			fmt.Fprintf(g.buf, "\t%s(t.%s, &dst)\n", field.ToFunc, field.Name)
+		}
+		g.buf.WriteString("\treturn dst\n")
+	}
+
+	g.buf.WriteString("}\n\n")
+}
+
+// generateFromExpr returns the expression populating a target field from its source field
+func (g *Generator) generateFromExpr(targetField types.FieldInfo, config types.MappingConfig) (string, bool) {
+	sourceField, sourceConfig, ok := g.findSourceField(targetField, config)
+	if !ok {
+		return "", false
+	}
+
+	// generate mapping expression
+	mappingExpr := g.generateFieldMapping(sourceField, targetField, sourceConfig)
+
+	// flattened fields stay zero if a struct on their path is nil
+	if pointers := g.pointerPaths(targetField.Source, config); len(pointers) > 0 {
+		targetFieldType, underlying := g.fieldTargetType(sourceField, targetField, sourceConfig)
+		zero := g.zeroValue(targetFieldType, underlying)
+		if optional, ok := sourceConfig.Optionals[targetField.Source]; ok {
+			zero = g.optionalNone(g.optionalElem(optional, true, sourceConfig), sourceConfig)
+		}
+		mappingExpr = fmt.Sprintf(`func() %s {
+		if src.%s == nil {
+			return %s
+		}
+		return %s
+	}()`, targetFieldType, strings.Join(pointers, " == nil || src."), zero, mappingExpr)
+	}
+
+	return mappingExpr, true
+}
+
+// fieldTargetType returns the type of a generated target field and its underlying type (see FieldInfo.Underlying)
+func (g *Generator) fieldTargetType(sf, tf types.FieldInfo, config types.MappingConfig) (string, string) {
+	if enumMap, ok := config.EnumMaps[tf.Source]; ok {
+		return g.typeRef(enumMap.Target, config), enumMap.Underlying
+	}
+	if optional, ok := config.Optionals[tf.Source]; ok {
+		return g.optionalType(g.optionalElem(optional, true, config), config), "struct"
+	}
+	if conversion, ok := config.Conversions[tf.Source]; ok {
+		return conversion.TargetKind, ""
+	}
+	sf = mirroredField(sf, tf, config)
+	return g.targetTypeName(sf.Type, config), sf.Underlying
+}
+
+// mirroredField returns a source field as its target field carries it, the value it points to if it's dereferenced
+//
+// Optional fields have no source type, their target is copied and compared as a whole. Converted fields
+// mirror the predeclared type of their target
+func mirroredField(sf, tf types.FieldInfo, config types.MappingConfig) types.FieldInfo {
+	if conversion, ok := config.Conversions[tf.Source]; ok {
+		sf.Type = conversion.TargetKind
+		sf.Underlying = conversion.TargetKind
+	}
+	if deref, ok := config.Derefs[tf.Source]; ok {
+		sf.Type = strings.TrimPrefix(sf.Type, "*")
+		sf.Underlying = deref.Underlying
+		sf.IsPointer = false
+	}
+	if _, ok := config.Optionals[tf.Source]; ok {
+		sf.Type = ""
+		sf.Underlying = "struct"
+		sf.IsPointer = false
+	}
+	return sf
+}
+
+// generateToFields writes the fields of a source struct literal for the struct at path,
+// rebuilding flattened structs from the target fields pulled up from them
+//
+// Returns the paths of the source fields left zero as the target doesn't carry them
+func (g *Generator) generateToFields(config, sourceConfig types.MappingConfig, path string) []string {
+	var zeroed []string
+	for _, sourceField := range sourceConfig.SourceType.Fields {
+		fieldPath := path + sourceField.Name
+
+		if targetField := g.findTargetField(fieldPath, config); targetField != nil {
+			// Generate reverse mapping expression
+			mappingExpr := g.generateReverseFieldMapping(*targetField, sourceField, sourceConfig)
+			fmt.Fprintf(g.buf, "\t\t%s: %s,\n", sourceField.Name, mappingExpr)
 			continue
 		}
 
-		// Get target field name (check for custom mapping)
-		targetFieldName := sourceField.Name
-		for srcName, tgtName := range config.FieldMap {
-			if srcName == sourceField.Name {
-				targetFieldName = tgtName
-				break
+		if nestedInfo, ok := config.NestedTypes[fieldPath]; ok {
+			nestedConfig := config
+			nestedConfig.SourceType = nestedInfo
+
+			literal := g.sourceTypeName(strings.TrimPrefix(sourceField.Type, "*"), sourceConfig)
+			if sourceField.IsPointer {
+				literal = "&" + literal
 			}
+
+			fmt.Fprintf(g.buf, "\t\t%s: %s{\n", sourceField.Name, literal)
+			zeroed = append(zeroed, g.generateToFields(config, nestedConfig, fieldPath+".")...)
+			g.buf.WriteString("\t\t},\n")
+			continue
 		}
 
-		// Create pseudo target field
-		targetFieldType := g.cleanTypeName(sourceField.Type, config)
-		targetField := types.FieldInfo{
-			Name:      targetFieldName,
-			Type:      targetFieldType,
-			IsPointer: sourceField.IsPointer,
-			IsSlice:   sourceField.IsSlice,
-			IsNested:  sourceField.IsNested,
+		// unexported fields of other packages can't be set, they're left zero
+		if !accessible(sourceField.Name, sourceConfig.SourceType, config) {
+			continue
 		}
 
-		// Generate reverse mapping expression
-		mappingExpr := g.generateReverseFieldMapping(targetField, sourceField, config)
-		fmt.Fprintf(g.buf, "\t\t%s: %s,\n", sourceField.Name, mappingExpr)
+		// Use zero value for fields omitted from the target, only struct literals name the type (and use its import)
+		zero := g.zeroValue(sourceField.Type, sourceField.Underlying)
+		if strings.HasSuffix(zero, "{}") {
+			zero = g.zeroValue(g.sourceTypeName(sourceField.Type, sourceConfig), sourceField.Underlying)
+		}
+		fmt.Fprintf(g.buf, "\t\t%s: %s,\n", sourceField.Name, zero)
+		zeroed = append(zeroed, fieldPath)
 	}
-
-	g.buf.WriteString("\t}\n")
-	g.buf.WriteString("}\n\n")
+	return zeroed
 }
 
-func (g *Generator) findTargetField(sourceField types.FieldInfo, config types.MappingConfig) *types.FieldInfo {
-	// Check if field is omitted
-	if config.OmitFields[sourceField.Name] {
-		return nil
+// findTargetField finds the target field mapped from a source field path
+//
+// Grouped fields are returned as a pseudo field named by their path within the target, eg: "Address.Line1"
+func (g *Generator) findTargetField(sourcePath string, config types.MappingConfig) *types.FieldInfo {
+	for i, tf := range config.TargetType.Fields {
+		if tf.Source == sourcePath {
+			return &config.TargetType.Fields[i]
+		}
 	}
 
-	// Check if there's a custom field mapping
-	if targetFieldName, ok := config.FieldMap[sourceField.Name]; ok {
-		for _, tf := range config.TargetType.Fields {
-			if tf.Name == targetFieldName {
+	for _, group := range config.Groups {
+		for _, tf := range group.Type.Fields {
+			if tf.Source == sourcePath {
+				tf.Name = group.Name + "." + tf.Name
 				return &tf
 			}
 		}
 	}
 
-	// Otherwise, look for field with same name
-	for _, tf := range config.TargetType.Fields {
-		if tf.Name == sourceField.Name {
-			return &tf
+	return nil
+}
+
+func (g *Generator) findExtraField(name string, config types.MappingConfig) *types.ExtraField {
+	for i, field := range config.ExtraFields {
+		if field.Name == name {
+			return &config.ExtraFields[i]
 		}
 	}
+	return nil
+}
 
+func (g *Generator) findGroup(name string, config types.MappingConfig) *types.FieldGroup {
+	for i, group := range config.Groups {
+		if group.Name == name {
+			return &config.Groups[i]
+		}
+	}
 	return nil
 }
 
+// findSourceField finds the source field a target field is mapped from, along with a
+// config whose source is the struct declaring it (for resolving its type)
+func (g *Generator) findSourceField(targetField types.FieldInfo, config types.MappingConfig) (types.FieldInfo, types.MappingConfig, bool) {
+	if targetField.Source == "" {
+		return types.FieldInfo{}, config, false
+	}
+
+	sourceConfig := config
+	name := targetField.Source
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		sourceConfig.SourceType = config.NestedTypes[name[:idx]]
+		name = name[idx+1:]
+	}
+
+	if sourceConfig.SourceType != nil {
+		for _, sourceField := range sourceConfig.SourceType.Fields {
+			if sourceField.Name == name {
+				return sourceField, sourceConfig, true
+			}
+		}
+	}
+
+	return types.FieldInfo{}, config, false
+}
+
+// pointerPaths returns the paths of the pointers on the way to a nested source field, eg: "Settings" for "Settings.Theme"
+func (g *Generator) pointerPaths(sourcePath string, config types.MappingConfig) []string {
+	var pointers []string
+
+	parts := strings.Split(sourcePath, ".")
+	structInfo := config.SourceType
+	for i := 0; i < len(parts)-1 && structInfo != nil; i++ {
+		path := strings.Join(parts[:i+1], ".")
+		for _, field := range structInfo.Fields {
+			if field.Name == parts[i] && field.IsPointer {
+				pointers = append(pointers, path)
+			}
+		}
+		structInfo = config.NestedTypes[path]
+	}
+
+	return pointers
+}
+
 func (g *Generator) generateFieldMapping(sf, tf types.FieldInfo, config types.MappingConfig) string {
-	if config.OmitFields[sf.Name] {
-		return g.zeroValue(tf.Type)
+	value := "src." + tf.Source
+
+	if enumMap, ok := config.EnumMaps[tf.Source]; ok {
+		return g.enumMapExpr(enumMap, enumMap.FromFunc, value, g.typeRef(enumMap.Target, config))
+	}
+
+	if deref, ok := config.Derefs[tf.Source]; ok {
+		return g.derefExpr(deref, value, sf, tf, config)
+	}
+
+	if optional, ok := config.Optionals[tf.Source]; ok {
+		return g.optionalExpr(optional, value, config)
 	}
 
-	// Handle slices first (including slices of nested structs)
-	if sf.IsSlice && tf.IsSlice {
-		return g.generateSliceMapping(sf, tf, config)
+	if conversion, ok := config.Conversions[tf.Source]; ok {
+		return g.conversionExpr(conversion, conversion.FromFunc, value, g.conversionTarget(conversion, tf, config), conversion.TargetKind)
 	}
 
-	// Handle nested structs
-	if sf.IsNested && tf.IsNested {
-		return g.generateNestedMapping(sf, tf, config)
+	if g.convertsBasic(sf, tf, config) {
+		return fmt.Sprintf("%s(%s)", g.existingTargetType(tf.Type, config), value)
 	}
 
-	// For primitive types, direct assignment
-	return fmt.Sprintf("src.%s", sf.Name)
+	// types without a registered mapping are assigned directly
+	sourceExpr := parseType(sf.Type)
+	if sourceExpr == nil || !g.needsConversion(sourceExpr, config) {
+		return g.copyValue(value, sourceExpr, config)
+	}
+
+	return g.generateValueMapping(value, sourceExpr, config)
 }
 
 func (g *Generator) generateReverseFieldMapping(tf, sf types.FieldInfo, config types.MappingConfig) string {
 	// This is the reverse mapping for To() method
 	// tf is target field (in our generated struct), sf is source field (in external struct)
 
-	// Clean types for comparison
-	sourceTypeClean := g.cleanTypeName(sf.Type, config)
-	targetTypeClean := g.cleanTypeName(tf.Type, config)
+	if enumMap, ok := config.EnumMaps[tf.Source]; ok {
+		return g.enumMapExpr(enumMap, enumMap.ToFunc, "t."+tf.Name, g.sourceTypeName(sf.Type, config))
+	}
+
+	if deref, ok := config.Derefs[tf.Source]; ok {
+		return g.addressExpr(deref, "t."+tf.Name, sf, tf, config)
+	}
 
-	if sourceTypeClean == targetTypeClean {
-		return fmt.Sprintf("t.%s", tf.Name)
+	if optional, ok := config.Optionals[tf.Source]; ok {
+		return g.presentExpr(optional, "t."+tf.Name, sf, config)
 	}
 
-	if tf.IsSlice && sf.IsSlice {
-		return g.generateReverseSliceMapping(tf, sf, config)
+	if conversion, ok := config.Conversions[tf.Source]; ok {
+		return g.conversionExpr(conversion, conversion.ToFunc, "t."+tf.Name, g.sourceTypeName(sf.Type, config), conversion.SourceKind)
 	}
 
-	if tf.IsNested && sf.IsNested {
-		return g.generateReverseNestedMapping(tf, sf, config)
+	if g.convertsBasic(sf, tf, config) {
+		return fmt.Sprintf("%s(t.%s)", g.sourceTypeName(sf.Type, config), tf.Name)
 	}
 
-	// Direct conversion
-	return fmt.Sprintf("t.%s", tf.Name)
+	sourceExpr := parseType(sf.Type)
+	if sourceExpr == nil || !g.needsConversion(sourceExpr, config) {
+		return g.copyValue("t."+tf.Name, sourceExpr, config)
+	}
+
+	return g.generateReverseValueMapping("t."+tf.Name, sourceExpr, config)
 }
 
-func (g *Generator) generateSliceMapping(sf, tf types.FieldInfo, config types.MappingConfig) string {
-	sourceElemType := strings.TrimPrefix(sf.Type, "[]")
-	targetElemType := strings.TrimPrefix(tf.Type, "[]")
-	targetElemTypeClean := g.cleanTypeName(targetElemType, config)
+// derefExpr converts a source pointer to the value its target field holds, the default if it's nil
+func (g *Generator) derefExpr(deref types.Deref, value string, sf, tf types.FieldInfo, config types.MappingConfig) string {
+	elem := mirroredField(sf, tf, config)
+	elemExpr := parseType(elem.Type)
+	typeStr := g.targetTypeName(elem.Type, config)
+
+	mappingExpr := g.copyValue("*"+value, elemExpr, config)
+	switch {
+	case g.convertsBasic(elem, tf, config):
+		typeStr = g.existingTargetType(tf.Type, config)
+		mappingExpr = fmt.Sprintf("%s(*%s)", typeStr, value)
+	case elemExpr != nil && g.needsConversion(elemExpr, config):
+		mappingExpr = g.generateValueMapping("(*"+value+")", elemExpr, config)
+	}
 
-	// check if needs conversion (is a struct)
-	if g.needsConversion(sourceElemType) {
-		return fmt.Sprintf(`func() []%s {
-		if src.%s == nil {
+	fallback := deref.Default
+	if fallback == "" {
+		fallback = g.zeroValue(typeStr, elem.Underlying)
+	}
+
+	return fmt.Sprintf(`func() %s {
+		if %s == nil {
+			return %s
+		}
+		return %s
+	}()`, typeStr, value, fallback, mappingExpr)
+}
+
+// addressExpr converts the value of a dereferenced target field back to a source pointer, nil for zero values with NilZero
+func (g *Generator) addressExpr(deref types.Deref, value string, sf, tf types.FieldInfo, config types.MappingConfig) string {
+	elem := mirroredField(sf, tf, config)
+	elemExpr := parseType(elem.Type)
+	typeStr := g.sourceTypeName(elem.Type, config)
+
+	mappingExpr := g.copyValue(value, elemExpr, config)
+	switch {
+	case g.convertsBasic(elem, tf, config):
+		mappingExpr = fmt.Sprintf("%s(%s)", typeStr, value)
+	case elemExpr != nil && g.needsConversion(elemExpr, config):
+		mappingExpr = g.generateReverseValueMapping(value, elemExpr, config)
+	}
+
+	if !deref.NilZero {
+		return fmt.Sprintf(`func() *%s {
+		result := %s
+		return &result
+	}()`, typeStr, mappingExpr)
+	}
+
+	return fmt.Sprintf(`func() *%s {
+		if %s {
+			result := %s
+			return &result
+		}
+		return nil
+	}()`, typeStr, g.nonZeroCheck(value, elemExpr, elem.Underlying, config), mappingExpr)
+}
+
+// generateValueMapping converts value (of source type sourceExpr) to its target type
+func (g *Generator) generateValueMapping(value string, sourceExpr ast.Expr, config types.MappingConfig) string {
+	switch t := sourceExpr.(type) {
+	case *ast.ArrayType:
+		return g.generateSliceMapping(value, t, config)
+	case *ast.MapType:
+		return g.generateMapMapping(value, t, config)
+	default:
+		return g.generateNestedMapping(value, sourceExpr, config)
+	}
+}
+
+// generateReverseValueMapping converts value (of the target type of sourceExpr) back to the source type
+func (g *Generator) generateReverseValueMapping(value string, sourceExpr ast.Expr, config types.MappingConfig) string {
+	switch t := sourceExpr.(type) {
+	case *ast.ArrayType:
+		return g.generateReverseSliceMapping(value, t, config)
+	case *ast.MapType:
+		return g.generateReverseMapMapping(value, t, config)
+	default:
+		return g.generateReverseNestedMapping(value, sourceExpr, config)
+	}
+}
+
+func (g *Generator) generateSliceMapping(value string, sourceExpr *ast.ArrayType, config types.MappingConfig) string {
+	return fmt.Sprintf(`func() []%s {
+%s
+	}()`, g.targetType(sourceExpr.Elt, config), g.sliceMappingBody(value, sourceExpr, config))
+}
+
+// sliceMappingBody returns the statements converting a source slice, ending with a return of the result
+func (g *Generator) sliceMappingBody(value string, sourceExpr *ast.ArrayType, config types.MappingConfig) string {
+	targetElemType := g.targetType(sourceExpr.Elt, config)
+
+	// registered struct elements are converted in place
+	if mapping := g.mappingOf(sourceExpr.Elt, config); mapping != nil && mapping.Enum == nil {
+		return fmt.Sprintf(`		if %s == nil {
 			return nil
 		}
-		result := make([]%s, len(src.%s))
-		for i, item := range src.%s {
-			converted := (&%s{}).From(&item)
+		result := make([]%s, len(%s))
+		for i, item := range %s {
+			converted := %s
 			if converted != nil {
 				result[i] = *converted
 			}
 		}
-		return result
-	}()`, targetElemTypeClean, sf.Name, targetElemTypeClean, sf.Name, sf.Name, targetElemTypeClean)
+		return result`, value, targetElemType, value, value, g.fromCall(mapping, "&item", config))
 	}
 
-	// direct copy for primitive/builtin slices
-	if sourceElemType == targetElemType {
-		return fmt.Sprintf("src.%s", sf.Name)
-	}
-
-	// casting
-	return fmt.Sprintf(`func() []%s {
-		result := make([]%s, len(src.%s))
-		for i, v := range src.%s {
-			result[i] = %s(v)
+	return fmt.Sprintf(`		if %s == nil {
+			return nil
 		}
-		return result
-	}()`, targetElemTypeClean, targetElemTypeClean, sf.Name, sf.Name, targetElemTypeClean)
+		result := make([]%s, len(%s))
+		for i, item := range %s {
+			result[i] = %s
+		}
+		return result`, value, targetElemType, value, value, g.generateValueMapping("item", sourceExpr.Elt, config))
 }
 
-func (g *Generator) generateReverseSliceMapping(tf, sf types.FieldInfo, config types.MappingConfig) string {
+func (g *Generator) generateReverseSliceMapping(value string, sourceExpr *ast.ArrayType, config types.MappingConfig) string {
 	// Reverse of slice mapping for To() method
-	sourceElemType := strings.TrimPrefix(sf.Type, "[]")
-	targetElemType := strings.TrimPrefix(tf.Type, "[]")
+	return fmt.Sprintf(`func() []%s {
+%s
+	}()`, g.sourceType(sourceExpr.Elt, config), g.reverseSliceMappingBody(value, sourceExpr, config))
+}
+
+// reverseSliceMappingBody returns the statements converting a target slice back, ending with a return of the result
+func (g *Generator) reverseSliceMappingBody(value string, sourceExpr *ast.ArrayType, config types.MappingConfig) string {
+	sourceElemType := g.sourceType(sourceExpr.Elt, config)
 
-	sourcePackage := config.SourceType.PackageName
+	return fmt.Sprintf(`		if %s == nil {
+			return nil
+		}
+		result := make([]%s, len(%s))
+		for i, item := range %s {
+			result[i] = %s
+		}
+		return result`, value, sourceElemType, value, value, g.generateReverseValueMapping("item", sourceExpr.Elt, config))
+}
 
-	// Check if element is a struct type
-	if tf.IsNested || strings.Contains(targetElemType, ".") {
-		// Extract just the type name without package
-		sourceElemTypeClean := strings.TrimPrefix(sourceElemType, sourcePackage+".")
+func (g *Generator) generateMapMapping(value string, sourceExpr *ast.MapType, config types.MappingConfig) string {
+	targetMapType := g.targetType(sourceExpr, config)
 
-		return fmt.Sprintf(`func() []%s.%s {
-		if t.%s == nil {
+	return fmt.Sprintf(`func() %s {
+		if %s == nil {
 			return nil
 		}
-		result := make([]%s.%s, len(t.%s))
-		for i, item := range t.%s {
-			result[i] = item.To()
+		result := make(%s, len(%s))
+		for key, item := range %s {
+			result[key] = %s
 		}
 		return result
-	}()`, sourcePackage, sourceElemTypeClean, tf.Name, sourcePackage, sourceElemTypeClean, tf.Name, tf.Name)
-	}
+	}()`, targetMapType, value, targetMapType, value, value, g.generateValueMapping("item", sourceExpr.Value, config))
+}
+
+func (g *Generator) generateReverseMapMapping(value string, sourceExpr *ast.MapType, config types.MappingConfig) string {
+	sourceMapType := g.sourceType(sourceExpr, config)
 
-	// For primitive slices
-	return fmt.Sprintf("t.%s", tf.Name)
+	return fmt.Sprintf(`func() %s {
+		if %s == nil {
+			return nil
+		}
+		result := make(%s, len(%s))
+		for key, item := range %s {
+			result[key] = %s
+		}
+		return result
+	}()`, sourceMapType, value, sourceMapType, value, value, g.generateReverseValueMapping("item", sourceExpr.Value, config))
 }
 
-func (g *Generator) generateNestedMapping(sf, tf types.FieldInfo, config types.MappingConfig) string {
-	targetTypeClean := g.cleanTypeName(tf.Type, config)
-	targetTypeClean = strings.TrimPrefix(targetTypeClean, "*")
+func (g *Generator) generateNestedMapping(value string, sourceExpr ast.Expr, config types.MappingConfig) string {
+	if star, ok := sourceExpr.(*ast.StarExpr); ok {
+		targetElemType := g.targetType(star.X, config)
 
-	if sf.IsPointer {
-		// nil check for source if pointer
-		return fmt.Sprintf(`func() *%s {
-		if src.%s != nil {
-			return (&%s{}).From(src.%s)
+		if mapping := g.mappingOf(star.X, config); mapping != nil && mapping.Enum == nil {
+			// nil check for source if pointer
+			return fmt.Sprintf(`func() *%s {
+		if %s != nil {
+			return %s
 		}
 		return nil
-	}()`, targetTypeClean, sf.Name, targetTypeClean, sf.Name)
+	}()`, targetElemType, value, g.fromCall(mapping, value, config))
+		}
+
+		return fmt.Sprintf(`func() *%s {
+		if %s == nil {
+			return nil
+		}
+		result := %s
+		return &result
+	}()`, targetElemType, value, g.generateValueMapping("(*"+value+")", star.X, config))
+	}
+
+	mapping := g.mappingOf(sourceExpr, config)
+	if mapping.Enum != nil {
+		return fmt.Sprintf("%s(%s)", mapping.FromFuncName, value)
 	}
 
+	targetTypeName := g.targetType(sourceExpr, config)
 	return fmt.Sprintf(`func() %s {
-		result := (&%s{}).From(&src.%s)
+		result := %s
 		if result != nil {
 			return *result
 		}
 		return %s{}
-	}()`, targetTypeClean, sf.Name, targetTypeClean, targetTypeClean)
+	}()`, targetTypeName, g.fromCall(mapping, addressOf(value), config), targetTypeName)
 }
 
-func (g *Generator) generateReverseNestedMapping(tf, sf types.FieldInfo, config types.MappingConfig) string {
-	// reverse nested mapping for To() method
-	sourcePackage := config.SourceType.PackageName
-	sourceTypeClean := g.cleanTypeName(sf.Type, config)
+// addressOf returns the address of an addressable value, the pointer itself for a dereferenced one, eg: "(*src.Address)"
+func addressOf(value string) string {
+	if strings.HasPrefix(value, "(*") && strings.HasSuffix(value, ")") {
+		return value[2 : len(value)-1]
+	}
+	return "&" + value
+}
 
-	typeName := strings.TrimPrefix(sourceTypeClean, sourcePackage+".")
-	typeName = strings.TrimPrefix(typeName, "*")
+func (g *Generator) generateReverseNestedMapping(value string, sourceExpr ast.Expr, config types.MappingConfig) string {
+	// reverse nested mapping for To() method
+	if star, ok := sourceExpr.(*ast.StarExpr); ok {
+		sourceElemType := g.sourceType(star.X, config)
 
-	if tf.IsPointer {
-		return fmt.Sprintf(`func() *%s.%s {
-		if t.%s != nil {
-			result := t.%s.To()
+		if mapping := g.mappingOf(star.X, config); mapping != nil && mapping.Enum == nil {
+			return fmt.Sprintf(`func() *%s {
+		if %s != nil {
+			result := %s
 			return &result
 		}
 		return nil
-	}()`, sourcePackage, typeName, tf.Name, tf.Name)
+	}()`, sourceElemType, value, g.toCall(mapping, value, true))
+		}
+
+		return fmt.Sprintf(`func() *%s {
+		if %s == nil {
+			return nil
+		}
+		result := %s
+		return &result
+	}()`, sourceElemType, value, g.generateReverseValueMapping("(*"+value+")", star.X, config))
+	}
+
+	mapping := g.mappingOf(sourceExpr, config)
+	if mapping.Enum != nil {
+		return fmt.Sprintf("%s(%s)", mapping.ToFuncName, value)
+	}
+	return g.toCall(mapping, value, false)
+}
+
+// checkTargetTypes verifies that every field of an existing target has the type its source field maps to
+func (g *Generator) checkTargetTypes(config types.MappingConfig) error {
+	var errs []error
+
+	for _, targetField := range config.TargetType.Fields {
+		sourceField, sourceConfig, ok := g.findSourceField(targetField, config)
+		if !ok {
+			continue
+		}
+		if _, ok := config.Optionals[targetField.Source]; ok {
+			if err := g.checkOptionalType(targetField, config); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if _, ok := config.Conversions[targetField.Source]; ok {
+			continue
+		}
+		sourceField = mirroredField(sourceField, targetField, config)
+
+		sourceExpr, targetExpr := parseType(sourceField.Type), parseType(targetField.Type)
+		if sourceExpr == nil || targetExpr == nil {
+			continue
+		}
+
+		// source type with registered mappings replaced by their targets
+		want := rewriteNamed(sourceExpr, func(pkg, name string) string {
+			return canonicalName(pkg, name, sourceConfig.SourceType.PackagePath, sourceConfig.SourceType.Imports)
+		}, func(expr ast.Expr) (string, bool) {
+			if mapping := g.mappingOf(expr, sourceConfig); mapping != nil {
+				return mapping.TargetType.PackagePath + "." + mapping.TargetType.TypeName, true
+			}
+			return "", false
+		})
+		got := canonicalType(targetExpr, config.TargetType.PackagePath, config.TargetType.Imports)
+
+		if enumMap, ok := config.EnumMaps[targetField.Source]; ok {
+			if want := enumMap.Target.PackagePath + "." + enumMap.Target.Name; want != got {
+				errs = append(errs, fmt.Errorf("%s.%s is %s but MapEnum converts %s.%s to %s.%s",
+					config.TargetType.TypeName, targetField.Name, targetField.Type,
+					config.SourceType.TypeName, targetField.Source, enumMap.Target.PackageName, enumMap.Target.Name))
+			}
+			continue
+		}
+
+		if want != got && !g.convertsBasic(sourceField, targetField, sourceConfig) {
+			errs = append(errs, fmt.Errorf("%s.%s is %s but is mapped from %s.%s (%s)",
+				config.TargetType.TypeName, targetField.Name, targetField.Type,
+				config.SourceType.TypeName, targetField.Source, sourceField.Type))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkNestedTo verifies that every mapping method converts nested values with generates a plain To
+func (g *Generator) checkNestedTo(config types.MappingConfig, method string) error {
+	targetFields := config.TargetType.Fields
+	for _, group := range config.Groups {
+		targetFields = append(targetFields, group.Type.Fields...)
+	}
+
+	for _, targetField := range targetFields {
+		sourceField, sourceConfig, ok := g.findSourceField(targetField, config)
+		if !ok {
+			continue
+		}
+		sourceExpr := parseType(sourceField.Type)
+		if sourceExpr == nil {
+			continue
+		}
+
+		if mapping := g.convertedMapping(sourceExpr, sourceConfig); mapping != nil && mapping.OmitPolicy != types.OmitZero {
+			return fmt.Errorf("%s.%s converts %s.%s with %s.To, which isn't generated due to its omit policy",
+				config.TargetType.TypeName, method, config.SourceType.TypeName, targetField.Source, mapping.TargetType.TypeName)
+		}
+	}
+
+	return nil
+}
+
+// convertsBasic reports whether a field of an existing target has another type than its source field with the same
+// predeclared underlying type (eg: string and api.Status), converted with a type conversion
+func (g *Generator) convertsBasic(sf, tf types.FieldInfo, config types.MappingConfig) bool {
+	if !config.ExistingTarget {
+		return false
+	}
+	if kind := basicKind(sf); kind == "" || kind != basicKind(tf) {
+		return false
+	}
+
+	sourceExpr, targetExpr := parseType(sf.Type), parseType(tf.Type)
+	return canonicalType(sourceExpr, config.SourceType.PackagePath, config.SourceType.Imports) !=
+		canonicalType(targetExpr, config.TargetType.PackagePath, config.TargetType.Imports)
+}
+
+// basicKind returns the predeclared basic type a field's type is (or is declared from), if any
+func basicKind(field types.FieldInfo) string {
+	kind := field.Underlying
+	if kind == "" {
+		kind = field.Type
+	}
+	switch kind {
+	case "error", "any", "comparable":
+		return ""
 	}
+	if predeclared[kind] {
+		return kind
+	}
+	return ""
+}
+
+// existingTargetType renders a field type of an existing target as seen from the generated package
+func (g *Generator) existingTargetType(typeStr string, config types.MappingConfig) string {
+	expr := parseType(typeStr)
+	if expr == nil {
+		return typeStr
+	}
+
+	target := config.TargetType
+	return rewriteType(expr, func(pkg, name string) string {
+		pkgPath, pkgName := target.PackagePath, target.PackageName
+		if pkg != "" {
+			var ok bool
+			if pkgPath, ok = target.Imports[pkg]; !ok {
+				return pkg + "." + name
+			}
+			pkgName = pkg
+		}
+		if pkgPath == config.OutputPath {
+			return name
+		}
+		return g.imports.alias(pkgPath, pkgName) + "." + name
+	})
+}
+
+// fromCall returns a call converting ptr (a pointer to a source value) with a registered mapping
+func (g *Generator) fromCall(mapping *types.MappingConfig, ptr string, config types.MappingConfig) string {
+	if mapping.FromFuncName != "" {
+		return fmt.Sprintf("%s(%s)", mapping.FromFuncName, ptr)
+	}
+	return fmt.Sprintf("(&%s{}).From(%s)", g.targetRef(mapping, config), ptr)
+}
 
-	return fmt.Sprintf(`func() %s.%s {
-		return t.%s.To()
-	}()`, sourcePackage, typeName, tf.Name)
+// toCall returns a call converting a target value back to its source with a registered mapping
+//
+// value must be addressable unless isPtr
+func (g *Generator) toCall(mapping *types.MappingConfig, value string, isPtr bool) string {
+	if mapping.ToFuncName != "" {
+		if !isPtr {
+			value = "&" + value
+		}
+		return fmt.Sprintf("%s(%s)", mapping.ToFuncName, value)
+	}
+	return fmt.Sprintf("%s.To()", value)
 }
 
 // --- Helpers ---
@@ -464,22 +1128,247 @@ func (g *Generator) isBuiltinType(typeName string) bool {
 	return builtins[clean]
 }
 
-func (g *Generator) needsConversion(typeName string) bool {
-	return !g.isPrimitiveType(typeName) && !g.isBuiltinType(typeName)
+// IsConverted reports whether a target field's value is converted from its source rather than assigned
+func (g *Generator) IsConverted(targetField types.FieldInfo, config types.MappingConfig) bool {
+	sourceField, sourceConfig, ok := g.findSourceField(targetField, config)
+	if !ok {
+		return false
+	}
+	if _, ok := config.EnumMaps[targetField.Source]; ok {
+		return true
+	}
+	if _, ok := config.Derefs[targetField.Source]; ok {
+		return true
+	}
+	if _, ok := config.Optionals[targetField.Source]; ok {
+		return true
+	}
+	if _, ok := config.Conversions[targetField.Source]; ok {
+		return true
+	}
+	if g.convertsBasic(sourceField, targetField, sourceConfig) {
+		return true
+	}
+
+	sourceExpr := parseType(sourceField.Type)
+	return sourceExpr != nil && g.needsConversion(sourceExpr, sourceConfig)
+}
+
+// copyValue returns value as is, or a deep copy of it in deep copy mode
+func (g *Generator) copyValue(value string, sourceExpr ast.Expr, config types.MappingConfig) string {
+	if !config.DeepCopy || sourceExpr == nil {
+		return value
+	}
+	if cloneExpr, ok := g.cloneExpr(value, sourceExpr, config); ok {
+		return cloneExpr
+	}
+	return value
+}
+
+// needsConversion reports whether a source type contains a registered mapping that must be converted
+func (g *Generator) needsConversion(sourceExpr ast.Expr, config types.MappingConfig) bool {
+	return g.convertedMapping(sourceExpr, config) != nil
+}
+
+// convertedMapping returns the registered mapping a source type is converted with, if any
+func (g *Generator) convertedMapping(sourceExpr ast.Expr, config types.MappingConfig) *types.MappingConfig {
+	switch t := sourceExpr.(type) {
+	case *ast.StarExpr:
+		return g.convertedMapping(t.X, config)
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return nil
+		}
+		return g.convertedMapping(t.Elt, config)
+	case *ast.MapType:
+		return g.convertedMapping(t.Value, config)
+	}
+
+	return g.mappingOf(sourceExpr, config)
+}
+
+// mappingOf returns the registered mapping of a named source type (or instantiation of a generic one), if any
+func (g *Generator) mappingOf(sourceExpr ast.Expr, config types.MappingConfig) *types.MappingConfig {
+	switch sourceExpr.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+		return g.mappings[canonicalType(sourceExpr, config.SourceType.PackagePath, config.SourceType.Imports)]
+	}
+	return nil
+}
+
+// resolvePackage returns the import path for a package qualifier used in the source package
+func (g *Generator) resolvePackage(pkg string, config types.MappingConfig) (string, bool) {
+	if pkg == "" {
+		return config.SourceType.PackagePath, true
+	}
+	pkgPath, ok := config.SourceType.Imports[pkg]
+	return pkgPath, ok
+}
+
+// qualifySource returns a reference to a type declared in (or imported by) the source package
+func (g *Generator) qualifySource(pkg, name string, config types.MappingConfig) string {
+	if pkg == "" && g.typeParams[name] {
+		return name
+	}
+
+	pkgPath, ok := g.resolvePackage(pkg, config)
+	if !ok {
+		// unknown package, leave the reference as written
+		return pkg + "." + name
+	}
+
+	// types declared in the generated package need no qualifier
+	if pkgPath == config.OutputPath {
+		return name
+	}
+
+	if pkg == "" {
+		pkg = config.SourceType.PackageName
+	}
+	return g.imports.alias(pkgPath, pkg) + "." + name
+}
+
+// targetType renders a source type as seen from the target package,
+// replacing registered types with their generated targets
+func (g *Generator) targetType(sourceExpr ast.Expr, config types.MappingConfig) string {
+	return rewriteNamed(sourceExpr, func(pkg, name string) string {
+		return g.qualifySource(pkg, name, config)
+	}, func(expr ast.Expr) (string, bool) {
+		if mapping := g.mappingOf(expr, config); mapping != nil {
+			return g.targetRef(mapping, config), true
+		}
+		// unregistered instantiations keep their source type arguments
+		switch expr.(type) {
+		case *ast.IndexExpr, *ast.IndexListExpr:
+			return g.sourceType(expr, config), true
+		}
+		return "", false
+	})
+}
+
+// targetRef returns a reference to the target type of a mapping from the generated package
+func (g *Generator) targetRef(mapping *types.MappingConfig, config types.MappingConfig) string {
+	target := mapping.TargetType
+	ref := target.TypeName
+	if mapping.ExistingTarget && target.PackagePath != config.OutputPath {
+		ref = g.imports.alias(target.PackagePath, target.PackageName) + "." + target.TypeName
+	}
+
+	// generic targets are referenced with their own type parameters
+	if len(target.TypeParams) > 0 {
+		names := make([]string, len(target.TypeParams))
+		for i, param := range target.TypeParams {
+			names[i] = param.Name
+		}
+		ref += "[" + strings.Join(names, ", ") + "]"
+	}
+	return ref
+}
+
+// sourceRef returns a reference to the source type of a mapping from the generated package,
+// with its type arguments (or parameters) if it's generic
+func (g *Generator) sourceRef(config types.MappingConfig) string {
+	return g.sourceType(sourceTypeExpr(config.SourceType), config)
+}
+
+// typeParamsDecl returns the type parameter list declaring a generic target and its converters, eg: "[T any]"
+func (g *Generator) typeParamsDecl(config types.MappingConfig) string {
+	params := config.SourceType.TypeParams
+	if len(params) == 0 {
+		return ""
+	}
+
+	decls := make([]string, len(params))
+	for i, param := range params {
+		decls[i] = param.Name + " " + g.sourceTypeName(param.Constraint, config)
+	}
+	return "[" + strings.Join(decls, ", ") + "]"
 }
 
-func (g *Generator) zeroValue(typeStr string) string {
-	switch typeStr {
+// sourceTypeExpr returns a struct type as written in its package, with its type arguments (or parameters) if it's generic
+func sourceTypeExpr(source *types.StructInfo) ast.Expr {
+	var args []string
+	args = append(args, source.TypeArgs...)
+	for _, param := range source.TypeParams {
+		args = append(args, param.Name)
+	}
+
+	if len(args) > 0 {
+		if expr := parseType(source.TypeName + "[" + strings.Join(args, ", ") + "]"); expr != nil {
+			return expr
+		}
+	}
+	return ast.NewIdent(source.TypeName)
+}
+
+// sourceType renders a source type as seen from the target package
+func (g *Generator) sourceType(sourceExpr ast.Expr, config types.MappingConfig) string {
+	return rewriteType(sourceExpr, func(pkg, name string) string {
+		return g.qualifySource(pkg, name, config)
+	})
+}
+
+func (g *Generator) targetTypeName(typeStr string, config types.MappingConfig) string {
+	if expr := parseType(typeStr); expr != nil {
+		return g.targetType(expr, config)
+	}
+	return typeStr
+}
+
+func (g *Generator) sourceTypeName(typeStr string, config types.MappingConfig) string {
+	if expr := parseType(typeStr); expr != nil {
+		return g.sourceType(expr, config)
+	}
+	return typeStr
+}
+
+// writeComment writes text as a line comment block, does nothing for empty text
+func (g *Generator) writeComment(indent, text string) {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			fmt.Fprintf(g.buf, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(g.buf, "%s// %s\n", indent, line)
+	}
+}
+
+// joinDoc joins doc comment paragraphs, skipping empty ones
+func joinDoc(paragraphs ...string) string {
+	var parts []string
+	for _, p := range paragraphs {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// zeroValue returns the zero value of a type, underlying describes named types (see FieldInfo.Underlying)
+func (g *Generator) zeroValue(typeStr, underlying string) string {
+	kind := typeStr
+	if underlying != "" {
+		kind = underlying
+	}
+
+	switch kind {
 	case "string":
 		return `""`
-	case "int", "int8", "int16", "int32", "int64":
+	case "int", "int8", "int16", "int32", "int64", "rune":
 		return "0"
-	case "uint", "uint8", "uint16", "uint32", "uint64":
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
 		return "0"
-	case "float32", "float64":
+	case "float32", "float64", "complex64", "complex128":
 		return "0"
 	case "bool":
 		return "false"
+	case "error", "any", "pointer", "slice", "map", "func", "chan", "interface":
+		return "nil"
 	default:
 		if strings.HasPrefix(typeStr, "*") || strings.HasPrefix(typeStr, "[]") || strings.HasPrefix(typeStr, "map[") {
 			return "nil"
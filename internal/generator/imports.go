@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Import is a single import of a generated file
+type Import struct {
+	Name string // explicit alias, empty if the package is imported by its own name
+	Path string
+}
+
+// IsStd reports whether the import is from the standard library
+func (i Import) IsStd() bool {
+	first, _, _ := strings.Cut(i.Path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// importPlanner assigns a unique alias to every package referenced by a generated file
+type importPlanner struct {
+	reserved map[string]bool   // identifiers that can't be used as aliases (eg: target package & types)
+	aliases  map[string]string // import path -> alias
+	paths    map[string]string // alias -> import path
+}
+
+func newImportPlanner(reserved ...string) *importPlanner {
+	p := &importPlanner{
+		reserved: make(map[string]bool, len(reserved)),
+		aliases:  make(map[string]string),
+		paths:    make(map[string]string),
+	}
+	for _, name := range reserved {
+		p.reserved[name] = true
+	}
+	return p
+}
+
+// alias returns the alias to qualify types from importPath with, adding the import if needed
+//
+// name is how the package is referred to in the source and is used as the alias if it's free
+func (p *importPlanner) alias(importPath, name string) string {
+	if alias, ok := p.aliases[importPath]; ok {
+		return alias
+	}
+
+	alias := name
+	if p.taken(alias) {
+		// prefix with the parent path element, eg: "github.com/x/billing/v1" -> "billingv1"
+		alias = identifier(path.Base(path.Dir(importPath))) + name
+		for i := 2; p.taken(alias); i++ {
+			alias = fmt.Sprintf("%s%d", name, i)
+		}
+	}
+
+	p.aliases[importPath] = alias
+	p.paths[alias] = importPath
+	return alias
+}
+
+func (p *importPlanner) taken(alias string) bool {
+	_, used := p.paths[alias]
+	return used || p.reserved[alias] || predeclared[alias]
+}
+
+// imports returns every import added to the file, standard library first and then sorted by path
+func (p *importPlanner) imports() []Import {
+	imports := make([]Import, 0, len(p.aliases))
+	for importPath, alias := range p.aliases {
+		imp := Import{Path: importPath}
+		if alias != path.Base(importPath) {
+			imp.Name = alias
+		}
+		imports = append(imports, imp)
+	}
+
+	sort.Slice(imports, func(i, j int) bool {
+		if imports[i].IsStd() != imports[j].IsStd() {
+			return imports[i].IsStd()
+		}
+		return imports[i].Path < imports[j].Path
+	})
+
+	return imports
+}
+
+// identifier strips everything that isn't valid in a Go identifier
+func identifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// optionalType renders the optional type of a target field holding values of type elem
+func (g *Generator) optionalType(elem string, config types.MappingConfig) string {
+	return g.typeRef(config.OptionalType.Type, config) + "[" + elem + "]"
+}
+
+// optionalFunc renders a package func of the optional type
+func (g *Generator) optionalFunc(name string, config types.MappingConfig) string {
+	ref := config.OptionalType.Type
+	ref.Name = name
+	return g.typeRef(ref, config)
+}
+
+// optionalNone returns an absent optional holding values of type elem
+func (g *Generator) optionalNone(elem string, config types.MappingConfig) string {
+	if config.OptionalType.None == "" {
+		return g.optionalType(elem, config) + "{}"
+	}
+	return fmt.Sprintf("%s[%s]()", g.optionalFunc(config.OptionalType.None, config), elem)
+}
+
+// optionalElem renders the type of a nullable field's value, as its target carries it if target is set
+func (g *Generator) optionalElem(optional types.Optional, target bool, config types.MappingConfig) string {
+	switch {
+	case optional.ElemRef != nil:
+		return g.typeRef(*optional.ElemRef, config)
+	case target:
+		return g.targetTypeName(optional.Elem, config)
+	default:
+		return g.sourceTypeName(optional.Elem, config)
+	}
+}
+
+// optionalExpr converts a nullable source value to an optional, absent for nil pointers and invalid sql values
+func (g *Generator) optionalExpr(optional types.Optional, value string, config types.MappingConfig) string {
+	elemExpr := parseType(optional.Elem)
+	if optional.ElemRef != nil {
+		elemExpr = nil
+	}
+	elem := g.optionalElem(optional, true, config)
+
+	absent, inner, converted := value+" == nil", "*"+value, "(*"+value+")"
+	if optional.Value != "" {
+		absent, inner = "!"+value+".Valid", value+"."+optional.Value
+		converted = inner
+	}
+
+	mappingExpr := g.copyValue(inner, elemExpr, config)
+	if elemExpr != nil && g.needsConversion(elemExpr, config) {
+		mappingExpr = g.generateValueMapping(converted, elemExpr, config)
+	}
+
+	return fmt.Sprintf(`func() %s {
+		if %s {
+			return %s
+		}
+		return %s(%s)
+	}()`, g.optionalType(elem, config), absent, g.optionalNone(elem, config), g.optionalFunc(config.OptionalType.Some, config), mappingExpr)
+}
+
+// presentExpr converts an optional target value back to its nullable source type, nil or invalid when it's absent
+func (g *Generator) presentExpr(optional types.Optional, value string, sf types.FieldInfo, config types.MappingConfig) string {
+	elemExpr := parseType(optional.Elem)
+	if optional.ElemRef != nil {
+		elemExpr = nil
+	}
+
+	mappingExpr := g.copyValue("v", elemExpr, config)
+	if elemExpr != nil && g.needsConversion(elemExpr, config) {
+		mappingExpr = g.generateReverseValueMapping("v", elemExpr, config)
+	}
+
+	if optional.Value != "" {
+		typeStr := g.sourceTypeName(sf.Type, config)
+		return fmt.Sprintf(`func() %s {
+		v, ok := %s.%s()
+		return %s{%s: %s, Valid: ok}
+	}()`, typeStr, value, config.OptionalType.Get, typeStr, optional.Value, mappingExpr)
+	}
+
+	result := "v"
+	if mappingExpr != "v" {
+		result = "result"
+		mappingExpr = fmt.Sprintf("result := %s\n", mappingExpr)
+	} else {
+		mappingExpr = ""
+	}
+	return fmt.Sprintf(`func() *%s {
+		if v, ok := %s.%s(); ok {
+			%sreturn &%s
+		}
+		return nil
+	}()`, g.optionalElem(optional, false, config), value, config.OptionalType.Get, mappingExpr, result)
+}
+
+// presentCheck returns a condition that holds when an optional target value is present
+func (g *Generator) presentCheck(value string, config types.MappingConfig) string {
+	return fmt.Sprintf(`func() bool {
+		_, ok := %s.%s()
+		return ok
+	}()`, value, config.OptionalType.Get)
+}
+
+// checkOptionalType verifies that a field of an existing target is an instantiation of the optional type
+func (g *Generator) checkOptionalType(targetField types.FieldInfo, config types.MappingConfig) error {
+	ref := config.OptionalType.Type
+	if index, ok := parseType(targetField.Type).(*ast.IndexExpr); ok {
+		got := canonicalType(index.X, config.TargetType.PackagePath, config.TargetType.Imports)
+		if got == ref.PackagePath+"."+ref.Name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s.%s is %s but Optional converts %s.%s to %s.%s",
+		config.TargetType.TypeName, targetField.Name, targetField.Type,
+		config.SourceType.TypeName, targetField.Source, ref.PackageName, ref.Name)
+}
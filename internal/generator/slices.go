@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// generateSliceFuncs writes the bulk converters of a mapping, converting every element with its From or To
+//
+// To converters are only generated when the mapping generates a plain To
+func (g *Generator) generateSliceFuncs(config types.MappingConfig) {
+	funcs := config.SliceFuncs
+	elem := sourceTypeExpr(config.SourceType)
+	values := &ast.ArrayType{Elt: elem}
+	pointers := &ast.ArrayType{Elt: &ast.StarExpr{X: elem}}
+
+	if funcs.From != "" {
+		fmt.Fprintf(g.buf, "// %s maps a slice of external structs to locals\n", funcs.From)
+		g.writeSliceFunc(funcs.From, values, config, false)
+	}
+	if funcs.PtrFrom != "" {
+		fmt.Fprintf(g.buf, "// %s maps a slice of external struct pointers to locals, nil elements stay nil\n", funcs.PtrFrom)
+		g.writeSliceFunc(funcs.PtrFrom, pointers, config, false)
+	}
+
+	if config.OmitPolicy != types.OmitZero {
+		return
+	}
+	if funcs.To != "" {
+		fmt.Fprintf(g.buf, "// %s maps a slice of local structs back to externals\n", funcs.To)
+		g.writeSliceFunc(funcs.To, values, config, true)
+	}
+	if funcs.PtrTo != "" {
+		fmt.Fprintf(g.buf, "// %s maps a slice of local struct pointers back to externals, nil elements stay nil\n", funcs.PtrTo)
+		g.writeSliceFunc(funcs.PtrTo, pointers, config, true)
+	}
+}
+
+// writeSliceFunc writes a bulk converter for a slice of the mapping's source (reverse) or target type
+func (g *Generator) writeSliceFunc(name string, sourceExpr *ast.ArrayType, config types.MappingConfig, reverse bool) {
+	sourceType, targetType := g.sourceType(sourceExpr, config), g.targetType(sourceExpr, config)
+
+	if reverse {
+		fmt.Fprintf(g.buf, "func %s%s(t %s) %s {\n", name, g.typeParamsDecl(config), targetType, sourceType)
+		g.buf.WriteString(g.reverseSliceMappingBody("t", sourceExpr, config))
+	} else {
+		fmt.Fprintf(g.buf, "func %s%s(src %s) %s {\n", name, g.typeParamsDecl(config), sourceType, targetType)
+		g.buf.WriteString(g.sliceMappingBody("src", sourceExpr, config))
+	}
+	g.buf.WriteString("\n}\n\n")
+}
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// timeFailure describes when a converter of a time field reports false, toTarget for the one converting from the source
+func timeFailure(conversion types.Conversion, toTarget bool) string {
+	switch {
+	case conversion.Time == types.TimeRFC3339 && !toTarget:
+		return "false if the value isn't an RFC 3339 time"
+	case conversion.Time == types.DurationMillis && !toTarget:
+		return "false if the value doesn't fit"
+	case conversion.Time == types.TimeUnix && toTarget:
+		return "dropping fractional seconds"
+	case conversion.Time == types.TimeUnixMilli && toTarget:
+		return "dropping fractional milliseconds"
+	}
+	return "every value fits"
+}
+
+// timeBodies returns the statements of both converters of a time field, from the source type to the target
+// (a number or a string) and back
+//
+// Zero times and durations (and nil pointers) convert to 0 or "", which convert back to zero times and durations
+// (or nil pointers)
+func (g *Generator) timeBodies(conversion types.Conversion, sourceType, targetType string) (string, string) {
+	timePkg := g.imports.alias("time", "time")
+	elemType := strings.TrimPrefix(sourceType, "*")
+	isPtr := strings.HasPrefix(conversion.SourceKind, "*")
+
+	sourceZero, isZero := elemType+"{}", "v.IsZero()"
+	if conversion.Time == types.DurationMillis {
+		sourceZero, isZero = "0", "v == 0"
+	}
+	if isPtr {
+		sourceZero, isZero = "nil", "v == nil || "+strings.Replace(isZero, "v == 0", "*v == 0", 1)
+	}
+	targetZero := "0"
+	if conversion.TargetKind == "string" {
+		targetZero = `""`
+	}
+
+	// v converted to the target, v converted back as r, and whether r is v
+	var toTarget, toSource, fits string
+	value := convertTo(conversion.TargetKind, targetType, "v")
+	switch conversion.Time {
+	case types.TimeUnix:
+		toTarget = "v.Unix()"
+		toSource = fmt.Sprintf("%s.Unix(%s, 0).UTC()", timePkg, value)
+	case types.TimeUnixMilli:
+		toTarget = "v.UnixMilli()"
+		toSource = fmt.Sprintf("%s.UnixMilli(%s).UTC()", timePkg, value)
+	case types.TimeRFC3339:
+		toTarget = fmt.Sprintf("v.Format(%s.RFC3339Nano)", timePkg)
+		toSource = fmt.Sprintf("%s.Parse(%s.RFC3339Nano, %s)", timePkg, timePkg, value)
+	case types.DurationMillis:
+		toTarget = "v.Milliseconds()"
+		toSource = fmt.Sprintf("%s(%s) * %s.Millisecond", elemType, value, timePkg)
+		fits = fmt.Sprintf("r/%s.Millisecond == %s(%s)", timePkg, elemType, value)
+	}
+
+	fromBody := fmt.Sprintf(`if %s {
+		return %s, true
+	}
+	return %s, true`, isZero, convertTo(targetType, conversion.TargetKind, targetZero), convertTo(targetType, conversion.TargetKind, toTarget))
+
+	result := "r"
+	if isPtr {
+		result = "&r"
+	}
+	toBody := fmt.Sprintf(`if v == %s {
+		return %s, true
+	}
+	`, targetZero, sourceZero)
+	switch {
+	case conversion.Time == types.TimeRFC3339:
+		toBody += fmt.Sprintf(`r, err := %s
+	if err != nil {
+		return %s, false
+	}
+	return %s, true`, toSource, sourceZero, result)
+	case fits != "":
+		toBody += fmt.Sprintf(`r := %s
+	return %s, %s`, toSource, result, fits)
+	case isPtr:
+		toBody += fmt.Sprintf(`r := %s
+	return &r, true`, toSource)
+	default:
+		toBody += fmt.Sprintf("return %s, true", toSource)
+	}
+
+	return fromBody, toBody
+}
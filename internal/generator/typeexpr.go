@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// predeclared types never need a package qualifier or conversion
+var predeclared = map[string]bool{
+	"bool": true, "string": true, "error": true, "any": true, "comparable": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+	"byte": true, "rune": true,
+}
+
+// parseType parses a field type as written in the source package
+//
+// Returns nil for types the reader couldn't fully describe (eg: "func(...)")
+func parseType(typeStr string) ast.Expr {
+	expr, err := parser.ParseExpr(typeStr)
+	if err != nil {
+		return nil
+	}
+	return expr
+}
+
+// rewriteType renders a type expression, replacing every named type with the result of resolve
+//
+// pkg is the qualifier used in the source file, or "" for types declared in the source package
+func rewriteType(expr ast.Expr, resolve func(pkg, name string) string) string {
+	return rewriteNamed(expr, resolve, nil)
+}
+
+// rewriteNamed is rewriteType, except that named types (instantiations included) are first passed to replace,
+// which may render them whole
+func rewriteNamed(expr ast.Expr, resolve func(pkg, name string) string, replace func(ast.Expr) (string, bool)) string {
+	rewrite := func(expr ast.Expr) string {
+		return rewriteNamed(expr, resolve, replace)
+	}
+
+	switch expr.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+		if replace != nil {
+			if typeStr, ok := replace(expr); ok {
+				return typeStr
+			}
+		}
+	}
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if predeclared[t.Name] {
+			return t.Name
+		}
+		return resolve("", t.Name)
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			return resolve(x.Name, t.Sel.Name)
+		}
+	case *ast.IndexExpr:
+		return rewrite(t.X) + "[" + rewrite(t.Index) + "]"
+	case *ast.IndexListExpr:
+		args := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			args[i] = rewrite(index)
+		}
+		return rewrite(t.X) + "[" + strings.Join(args, ", ") + "]"
+	case *ast.StarExpr:
+		return "*" + rewrite(t.X)
+	case *ast.ParenExpr:
+		return "(" + rewrite(t.X) + ")"
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + rewrite(t.Elt)
+		}
+		return "[" + exprString(t.Len) + "]" + rewrite(t.Elt)
+	case *ast.MapType:
+		return "map[" + rewrite(t.Key) + "]" + rewrite(t.Value)
+	case *ast.UnaryExpr:
+		// ~T in constraints
+		return t.Op.String() + rewrite(t.X)
+	case *ast.BinaryExpr:
+		// unions in constraints
+		return rewrite(t.X) + " " + t.Op.String() + " " + rewrite(t.Y)
+	case *ast.ChanType:
+		return exprString(&ast.ChanType{Dir: t.Dir, Value: ast.NewIdent(rewrite(t.Value))})
+	}
+
+	return exprString(expr)
+}
+
+// namedType returns the package qualifier and name of a named type expression
+func namedType(expr ast.Expr) (pkg, name string, ok bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if predeclared[t.Name] {
+			return "", "", false
+		}
+		return "", t.Name, true
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			return x.Name, t.Sel.Name, true
+		}
+	}
+	return "", "", false
+}
+
+// canonicalType renders a type with full import paths so types written in different packages can be compared
+//
+// pkgPath and imports describe the package the type is written in
+func canonicalType(expr ast.Expr, pkgPath string, imports map[string]string) string {
+	return rewriteType(expr, func(pkg, name string) string {
+		return canonicalName(pkg, name, pkgPath, imports)
+	})
+}
+
+func canonicalName(pkg, name, pkgPath string, imports map[string]string) string {
+	if pkg == "" {
+		return pkgPath + "." + name
+	}
+	if importPath, ok := imports[pkg]; ok {
+		return importPath + "." + name
+	}
+	return pkg + "." + name
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
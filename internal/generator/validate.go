@@ -0,0 +1,520 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// ValidationErrorType is the type Validate reports failures with and PrefixValidationErrors the func nesting them,
+// both generated once per target package
+const (
+	ValidationErrorType    = "ValidationError"
+	PrefixValidationErrors = "prefixValidationErrors"
+)
+
+// GenerateValidationError generates the ValidationError type without package/imports
+func (g *Generator) GenerateValidationError() string {
+	g.imports = newImportPlanner()
+
+	return fmt.Sprintf(`// %[1]s is a field failing a rule, see Validate
+type %[1]s struct {
+	Path string // path of the field within the model, eg: "Settings.Theme", "Items[2].Name"
+	Err  error
+}
+
+func (e *%[1]s) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *%[1]s) Unwrap() error {
+	return e.Err
+}
+
+// %[2]s prepends path to the paths of the failures returned by Validate of a nested model
+func %[2]s(path string, err error) []error {
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	prefixed := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if failure, ok := err.(*%[1]s); ok {
+			prefixed = append(prefixed, &%[1]s{Path: path + "." + failure.Path, Err: failure.Err})
+			continue
+		}
+		prefixed = append(prefixed, &%[1]s{Path: path, Err: err})
+	}
+	return prefixed
+}
+`, ValidationErrorType, PrefixValidationErrors)
+}
+
+// ruleCheck is a condition a field fails a rule on, with the message it's reported with
+type ruleCheck struct {
+	cond string
+	msg  string
+}
+
+// generateValidateMethod writes Validate, which checks the rules of the mapped fields and the registered nested
+// types with Validate, followed by the patterns of its Match rules
+func (g *Generator) generateValidateMethod(config types.MappingConfig) {
+	targetType := config.TargetType.TypeName
+	targetRef := g.targetRef(&config, config)
+
+	name := "Validate"
+	if config.ValidateFuncName != "" {
+		name = config.ValidateFuncName
+	}
+	fmt.Fprintf(g.buf, "// %s checks the rules of the mapped fields and registered nested types, returning every failure\n", name)
+	fmt.Fprintf(g.buf, "// as a *%s, joined\n", ValidationErrorType)
+	g.buf.WriteString("//\n")
+	if config.ValidateFuncName != "" {
+		fmt.Fprintf(g.buf, "// Usage: err := %s(&local%s)\n", name, targetType)
+		fmt.Fprintf(g.buf, "func %s%s(t *%s) error {\n", name, g.typeParamsDecl(config), targetRef)
+	} else {
+		fmt.Fprintf(g.buf, "// Usage: err := local%s.Validate()\n", targetType)
+		fmt.Fprintf(g.buf, "func (t *%s) Validate() error {\n", targetRef)
+	}
+	g.buf.WriteString("\tif t == nil {\n")
+	g.buf.WriteString("\t\treturn nil\n")
+	g.buf.WriteString("\t}\n\n")
+	g.buf.WriteString("\tvar errs []error\n")
+
+	var patterns []types.Rule
+	fields, paths := g.mappedFields(config)
+	for i, targetField := range fields {
+		sourceField, sourceConfig, ok := g.findSourceField(targetField, config)
+		if !ok {
+			continue
+		}
+		value := "t." + paths[i]
+		mirrored := mirroredField(sourceField, targetField, config)
+
+		checks := g.ruleChecks(value, targetField, mirrored, config, sourceConfig)
+		for j, check := range checks {
+			if j > 0 {
+				g.buf.WriteString(" else ")
+			} else {
+				g.buf.WriteString("\t")
+			}
+			fmt.Fprintf(g.buf, "if %s {\n", check.cond)
+			fmt.Fprintf(g.buf, "\t\terrs = append(errs, &%s{Path: %q, Err: %s(%q)})\n",
+				ValidationErrorType, paths[i], g.imports.alias("errors", "errors")+".New", check.msg)
+			g.buf.WriteString("\t}")
+		}
+		if len(checks) > 0 {
+			g.buf.WriteString("\n")
+		}
+		for _, rule := range config.Rules[targetField.Source] {
+			if rule.Kind == types.RuleMatch {
+				patterns = append(patterns, rule)
+			}
+		}
+
+		// converted fields don't hold the source type anymore
+		_, enumMapped := config.EnumMaps[targetField.Source]
+		_, converted := config.Conversions[targetField.Source]
+		if !enumMapped && !converted && mirrored.Type != "" {
+			g.generateNestedValidate(value, paths[i], parseType(mirrored.Type), sourceConfig)
+		}
+	}
+
+	g.buf.WriteString("\treturn " + g.imports.alias("errors", "errors") + ".Join(errs...)\n")
+	g.buf.WriteString("}\n\n")
+
+	for _, rule := range patterns {
+		fmt.Fprintf(g.buf, "var %s = %s.MustCompile(%s)\n\n", rule.Var, g.imports.alias("regexp", "regexp"), rawString(rule.Pattern))
+	}
+}
+
+// generateNestedValidate writes the calls validating a field holding registered types with Validate:
+// directly, through a pointer, or as the items of a slice or map
+func (g *Generator) generateNestedValidate(value, path string, sourceExpr ast.Expr, config types.MappingConfig) {
+	if mapping, isPtr := g.validateMapping(sourceExpr, config); mapping != nil {
+		fmt.Fprintf(g.buf, "\terrs = append(errs, %s(%q, %s)...)\n", PrefixValidationErrors, path, g.validateCall(mapping, value, isPtr))
+		return
+	}
+
+	switch t := sourceExpr.(type) {
+	case *ast.ArrayType:
+		if mapping, isPtr := g.validateMapping(t.Elt, config); mapping != nil {
+			fmt.Fprintf(g.buf, "\tfor i := range %s {\n", value)
+			fmt.Fprintf(g.buf, "\t\terrs = append(errs, %s(%s(%q, i), %s)...)\n", PrefixValidationErrors, g.imports.alias("fmt", "fmt")+".Sprintf", path+"[%d]",
+				g.validateCall(mapping, value+"[i]", isPtr))
+			g.buf.WriteString("\t}\n")
+		}
+	case *ast.MapType:
+		if mapping, isPtr := g.validateMapping(t.Value, config); mapping != nil {
+			fmt.Fprintf(g.buf, "\tfor key, item := range %s {\n", value)
+			fmt.Fprintf(g.buf, "\t\terrs = append(errs, %s(%s(%q, key), %s)...)\n", PrefixValidationErrors, g.imports.alias("fmt", "fmt")+".Sprintf", path+"[%v]",
+				g.validateCall(mapping, "item", isPtr))
+			g.buf.WriteString("\t}\n")
+		}
+	}
+}
+
+// validateMapping returns the registered mapping with Validate a source type (or pointer to it) is converted with
+func (g *Generator) validateMapping(sourceExpr ast.Expr, config types.MappingConfig) (*types.MappingConfig, bool) {
+	star, isPtr := sourceExpr.(*ast.StarExpr)
+	if isPtr {
+		sourceExpr = star.X
+	}
+	if mapping := g.mappingOf(sourceExpr, config); mapping != nil && mapping.Validate {
+		return mapping, isPtr
+	}
+	return nil, false
+}
+
+// validateCall returns a call validating a target value with a registered mapping
+//
+// value must be addressable unless isPtr
+func (g *Generator) validateCall(mapping *types.MappingConfig, value string, isPtr bool) string {
+	if mapping.ValidateFuncName != "" {
+		if !isPtr {
+			value = "&" + value
+		}
+		return fmt.Sprintf("%s(%s)", mapping.ValidateFuncName, value)
+	}
+	return fmt.Sprintf("%s.Validate()", value)
+}
+
+// ruleChecks returns the conditions a target field fails its rules on, in order. mirrored is the source field as
+// the target carries it, see mirroredField
+//
+// Pointers are checked by the value they point to, nil pointers only fail Required. The rules are expected to apply
+// to the field, see CheckRule
+func (g *Generator) ruleChecks(value string, tf, mirrored types.FieldInfo, config, sourceConfig types.MappingConfig) []ruleCheck {
+	rules := config.Rules[tf.Source]
+	if len(rules) == 0 {
+		return nil
+	}
+
+	// optional types only tell whether they hold a value
+	if _, ok := config.Optionals[tf.Source]; ok {
+		var checks []ruleCheck
+		for _, rule := range rules {
+			if rule.Kind == types.RuleRequired {
+				checks = append(checks, ruleCheck{"!" + g.presentCheck(value, config), "is required"})
+			}
+		}
+		return checks
+	}
+
+	sourceExpr, underlying := parseType(mirrored.Type), mirrored.Underlying
+	if enumMap, ok := config.EnumMaps[tf.Source]; ok {
+		sourceExpr, underlying = ast.NewIdent(enumMap.Underlying), ""
+	}
+
+	// rules other than Required check the value a pointer points to
+	elem, elemExpr := value, sourceExpr
+	star, isPtr := sourceExpr.(*ast.StarExpr)
+	if isPtr {
+		elem, elemExpr, underlying = "*"+value, star.X, ""
+	}
+	kind := ruleKind(elemExpr, underlying)
+
+	guard := ""
+	for _, rule := range rules {
+		switch {
+		case isPtr:
+			guard = value + " != nil && "
+		case rule.Kind == types.RuleOmitEmpty:
+			guard = g.nonZeroCheck(value, sourceExpr, mirrored.Underlying, sourceConfig) + " && "
+		}
+	}
+
+	var checks []ruleCheck
+	for _, rule := range rules {
+		switch rule.Kind {
+		case types.RuleRequired:
+			checks = append(checks, ruleCheck{negate(g.nonZeroCheck(value, sourceExpr, mirrored.Underlying, sourceConfig)), "is required"})
+			continue
+		case types.RuleOmitEmpty:
+			continue
+		}
+
+		for _, check := range g.valueChecks(elem, elemExpr, kind, rule) {
+			check.cond = guard + check.cond
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// valueChecks returns the conditions a value of a basic, slice or map type fails a rule other than Required on
+func (g *Generator) valueChecks(value string, sourceExpr ast.Expr, kind string, rule types.Rule) []ruleCheck {
+	rule.Kind = sizeKind(rule.Kind, kind)
+
+	// named string types are converted for the functions taking strings
+	str := value
+	if ident, ok := sourceExpr.(*ast.Ident); !ok || ident.Name != "string" {
+		str = "string(" + value + ")"
+	}
+
+	var checks []ruleCheck
+	switch rule.Kind {
+	case types.RuleLen:
+		length, format := "len("+value+")", "must have %s %s items"
+		if rule.Max == "1" || (rule.Max == "" && rule.Min == "1") {
+			format = "must have %s %s item"
+		}
+		if kind == "string" {
+			length, format = g.imports.alias("unicode/utf8", "utf8")+".RuneCountInString("+str+")", "must be %s %s characters long"
+		}
+		if rule.Min != "" && rule.Min == rule.Max {
+			checks = append(checks, ruleCheck{length + " != " + rule.Min, fmt.Sprintf(format, "exactly", rule.Min)})
+			break
+		}
+		if rule.Min != "" {
+			checks = append(checks, ruleCheck{length + " < " + rule.Min, fmt.Sprintf(format, "at least", rule.Min)})
+		}
+		if rule.Max != "" {
+			checks = append(checks, ruleCheck{length + " > " + rule.Max, fmt.Sprintf(format, "at most", rule.Max)})
+		}
+
+	case types.RuleRange:
+		if rule.Min != "" && rule.Min == rule.Max {
+			checks = append(checks, ruleCheck{value + " != " + rule.Min, "must be " + rule.Min})
+			break
+		}
+		if rule.Min != "" {
+			checks = append(checks, ruleCheck{value + " < " + rule.Min, "must be at least " + rule.Min})
+		}
+		if rule.Max != "" {
+			checks = append(checks, ruleCheck{value + " > " + rule.Max, "must be at most " + rule.Max})
+		}
+
+	case types.RuleMatch:
+		msg := "must match " + rule.Pattern
+		if rule.Desc != "" {
+			msg = "must be " + rule.Desc
+		}
+		checks = append(checks, ruleCheck{"!" + rule.Var + ".MatchString(" + str + ")", msg})
+
+	case types.RuleOneOf:
+		conds := make([]string, len(rule.Values))
+		names := make([]string, len(rule.Values))
+		for i, v := range rule.Values {
+			conds[i] = value + " != " + v
+			names[i] = v
+			if s, err := strconv.Unquote(v); err == nil {
+				names[i] = s
+			}
+		}
+		checks = append(checks, ruleCheck{strings.Join(conds, " && "), "must be one of " + strings.Join(names, ", ")})
+	}
+	return checks
+}
+
+// CheckRule verifies that a rule applies to a field, optional or holding values rules check as kind (see RuleKind)
+func CheckRule(rule types.Rule, kind string, optional bool) error {
+	name := ruleName(rule)
+	switch {
+	case rule.Kind == types.RuleRequired, rule.Kind == types.RuleOmitEmpty:
+		return nil
+	case optional:
+		// optional types only tell whether they hold a value
+		return fmt.Errorf("%s doesn't apply to optional fields", name)
+	}
+
+	typ := kind
+	if typ == "" {
+		typ = "a struct, pointer or interface"
+	}
+	_, number := numericBits[kind]
+	switch sizeKind(rule.Kind, kind) {
+	case types.RuleLen:
+		if kind != "string" && kind != "slice" && kind != "map" {
+			return fmt.Errorf("%s needs a string, slice or map, it's %s", name, typ)
+		}
+	case types.RuleRange:
+		if !number {
+			return fmt.Errorf("%s needs a number, it's %s", name, typ)
+		}
+		for _, bound := range []string{rule.Min, rule.Max} {
+			if err := checkLiteral(bound, kind); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	case types.RuleMatch:
+		if kind != "string" {
+			return fmt.Errorf("%s needs a string, it's %s", name, typ)
+		}
+	case types.RuleOneOf:
+		if !number && kind != "string" && kind != "bool" {
+			return fmt.Errorf("%s needs a basic type, it's %s", name, typ)
+		}
+		for _, v := range rule.Values {
+			if err := checkLiteral(v, kind); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RuleKind returns the predeclared type rules check a value of a type as (see FieldInfo.Underlying), pointers by the
+// value they point to; "slice" or "map" for those, empty for other types
+func RuleKind(typeStr, underlying string) string {
+	sourceExpr := parseType(typeStr)
+	if star, ok := sourceExpr.(*ast.StarExpr); ok {
+		sourceExpr, underlying = star.X, ""
+	}
+	if sourceExpr == nil {
+		return ""
+	}
+	return ruleKind(sourceExpr, underlying)
+}
+
+// sizeKind returns the kind RuleSize checks on a value of kind: RuleRange for numbers, RuleLen otherwise
+func sizeKind(rule types.RuleKind, kind string) types.RuleKind {
+	if rule != types.RuleSize {
+		return rule
+	}
+	if _, ok := numericBits[kind]; ok {
+		return types.RuleRange
+	}
+	return types.RuleLen
+}
+
+// ruleKind returns the predeclared type rules check a value (of the target type mapped from sourceExpr) as,
+// "slice" or "map" for those, empty for other types
+func ruleKind(sourceExpr ast.Expr, underlying string) string {
+	switch t := sourceExpr.(type) {
+	case *ast.Ident:
+		if predeclared[t.Name] {
+			return t.Name
+		}
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "slice"
+		}
+		return ""
+	case *ast.MapType:
+		return "map"
+	}
+	switch {
+	case predeclared[underlying], underlying == "slice", underlying == "map":
+		return underlying
+	}
+	return ""
+}
+
+// checkLiteral verifies that a Go literal from a rule (or an empty bound) can be compared with a value of kind
+func checkLiteral(literal, kind string) error {
+	if literal == "" {
+		return nil
+	}
+
+	class := "number"
+	switch {
+	case strings.HasPrefix(literal, `"`):
+		class = "string"
+	case literal == "true" || literal == "false":
+		class = "bool"
+	}
+	want := kind
+	if _, ok := numericBits[kind]; ok {
+		want = "number"
+	}
+	if class != want {
+		return fmt.Errorf("can't compare %s with %s", literal, kind)
+	}
+
+	if class == "number" && !isFloat(kind) {
+		if strings.ContainsAny(literal, ".eE") {
+			return fmt.Errorf("%s is not an integer", literal)
+		}
+		if isUnsigned(kind) && strings.HasPrefix(literal, "-") {
+			return fmt.Errorf("%s is negative, the field is %s", literal, kind)
+		}
+	}
+	return nil
+}
+
+// ruleName names a rule in messages, after the modelgen func creating it
+func ruleName(rule types.Rule) string {
+	switch rule.Kind {
+	case types.RuleRequired:
+		return "Required"
+	case types.RuleLen:
+		return bounded("Len", rule)
+	case types.RuleRange:
+		switch {
+		case rule.Min == "":
+			return "Max"
+		case rule.Max == "":
+			return "Min"
+		}
+		return "Range"
+	case types.RuleMatch:
+		return "Match"
+	case types.RuleSize:
+		// named after the validate tag rule
+		switch {
+		case rule.Min == "":
+			return "max"
+		case rule.Max == "":
+			return "min"
+		}
+		return "len"
+	case types.RuleOmitEmpty:
+		return "omitempty"
+	}
+	return "OneOf"
+}
+
+// bounded names a rule with an optional Min and Max, eg: MinLen
+func bounded(name string, rule types.Rule) string {
+	switch {
+	case rule.Min == "":
+		return "Max" + name
+	case rule.Max == "":
+		return "Min" + name
+	}
+	return name
+}
+
+// negate returns the opposite of a condition returned by nonZeroCheck
+func negate(cond string) string {
+	switch {
+	case strings.HasPrefix(cond, "!"):
+		return cond[1:]
+	case strings.Contains(cond, " != "):
+		return strings.Replace(cond, " != ", " == ", 1)
+	}
+	return "!" + cond
+}
+
+// rawString renders a string as a raw string literal if it can be written as one
+func rawString(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// fromFailure describes what FromE fails on
+func fromFailure(config types.MappingConfig, fields []checkedField) string {
+	if !config.ValidateFromE {
+		return "failing on the values it can't convert"
+	}
+	name := "Validate"
+	if config.ValidateFuncName != "" {
+		name = config.ValidateFuncName
+	}
+	for _, field := range fields {
+		if field.fromFunc != "" {
+			return "failing on the values it can't convert and on models " + name + " rejects"
+		}
+	}
+	return "failing on models " + name + " rejects"
+}
@@ -1,27 +1,173 @@
 package mapper
 
-import "github.com/matt0792/modelgen/internal/types"
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
 
-type FieldMatcher struct{}
+	"github.com/matt0792/modelgen/internal/types"
+)
 
-func (m *FieldMatcher) MatchFields(source, target *types.StructInfo) map[string]string {
-	matches := make(map[string]string)
-	targetFields := make(map[string]bool)
+// Strategy decides whether a source and target field match
+type Strategy func(source, target types.FieldInfo) bool
+
+var (
+	// Exact matches identical names
+	Exact Strategy = func(source, target types.FieldInfo) bool {
+		return source.Name == target.Name
+	}
+
+	// CaseInsensitive matches names that only differ in case
+	CaseInsensitive Strategy = func(source, target types.FieldInfo) bool {
+		return strings.EqualFold(source.Name, target.Name)
+	}
+
+	// Initialisms matches names that only differ in the case of initialisms, eg: UserId -> UserID
+	Initialisms Strategy = func(source, target types.FieldInfo) bool {
+		return normalizeInitialisms(source.Name) == normalizeInitialisms(target.Name)
+	}
+
+	// JSONTag matches fields with the same json tag name
+	JSONTag Strategy = func(source, target types.FieldInfo) bool {
+		sourceName, targetName := jsonName(source), jsonName(target)
+		return sourceName != "" && sourceName == targetName
+	}
+
+	// SnakeCamel matches snake_case names to their CamelCase equivalent, eg: user_id -> UserID
+	SnakeCamel Strategy = func(source, target types.FieldInfo) bool {
+		return foldSnake(source.Name) == foldSnake(target.Name)
+	}
+)
 
-	for _, tf := range target.Fields {
-		targetFields[tf.Name] = true
+// initialisms normalized by the Initialisms strategy
+var initialisms = map[string]bool{
+	"API": true, "DB": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SQL": true, "TCP": true, "TLS": true, "TTL": true, "UI": true,
+	"URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+type FieldMatcher struct {
+	Strategies []Strategy // tried in priority order, defaults to Exact
+}
+
+// MatchFields matches source fields to target fields, returning source name -> target name
+//
+// Each strategy only considers fields left unmatched by the ones before it. A field matching
+// more than one field within a strategy is ambiguous and reported as an error
+func (m *FieldMatcher) MatchFields(source, target *types.StructInfo) (map[string]string, error) {
+	strategies := m.Strategies
+	if len(strategies) == 0 {
+		strategies = []Strategy{Exact}
 	}
 
-	for _, sf := range source.Fields {
-		if targetFields[sf.Name] {
-			matches[sf.Name] = sf.Name
+	matches := make(map[string]string)
+	matchedTargets := make(map[string]bool)
+	var ambiguous []string
+
+	for _, strategy := range strategies {
+		candidates := make(map[string][]string) // source -> targets
+		claimedBy := make(map[string][]string)  // target -> sources
+
+		for _, sf := range source.Fields {
+			if _, ok := matches[sf.Name]; ok {
+				continue
+			}
+			for _, tf := range target.Fields {
+				if matchedTargets[tf.Name] || !strategy(sf, tf) {
+					continue
+				}
+				candidates[sf.Name] = append(candidates[sf.Name], tf.Name)
+				claimedBy[tf.Name] = append(claimedBy[tf.Name], sf.Name)
+			}
+		}
+
+		for sourceName, targetNames := range candidates {
+			if len(targetNames) > 1 {
+				ambiguous = append(ambiguous, fmt.Sprintf("%s matches %s", sourceName, strings.Join(targetNames, ", ")))
+				continue
+			}
+			if sourceNames := claimedBy[targetNames[0]]; len(sourceNames) > 1 {
+				ambiguous = append(ambiguous, fmt.Sprintf("%s matches %s", targetNames[0], strings.Join(sourceNames, ", ")))
+				continue
+			}
+			matches[sourceName] = targetNames[0]
+			matchedTargets[targetNames[0]] = true
 		}
 	}
 
-	return matches
+	if len(ambiguous) > 0 {
+		sort.Strings(ambiguous)
+		return nil, fmt.Errorf("ambiguous field matches: %s", strings.Join(unique(ambiguous), "; "))
+	}
+
+	return matches, nil
 }
 
 func (m *FieldMatcher) NeedsRecursiveMapping(sourceField, targetField types.FieldInfo) bool {
 	// if both are struct types aith the same name, we need to generate recursive mapping
 	return sourceField.IsNested && targetField.IsNested
 }
+
+// --- Helpers ---
+
+// normalizeInitialisms upper cases every word of a name that's a known initialism
+func normalizeInitialisms(name string) string {
+	words := splitWords(name)
+	for i, word := range words {
+		if initialisms[strings.ToUpper(word)] {
+			words[i] = strings.ToUpper(word)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits a CamelCase name into words, keeping runs of capitals together, eg: "HTTPServerId" -> HTTP, Server, Id
+func splitWords(name string) []string {
+	runes := []rune(name)
+
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsUpper(runes[i]) && (prevLower || nextLower) || runes[i] == '_' {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i
+			if runes[i] == '_' {
+				start++
+			}
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+func foldSnake(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// jsonName returns the name from a field's json tag, empty if there is none or the field is skipped
+func jsonName(field types.FieldInfo) string {
+	name, _, _ := strings.Cut(reflect.StructTag(field.Tag).Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func unique(sorted []string) []string {
+	var result []string
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package mapper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// structOf builds a struct with fields named by names, "Name `tag`" sets a field's tag
+func structOf(names ...string) *types.StructInfo {
+	info := &types.StructInfo{TypeName: "T"}
+	for _, name := range names {
+		field := types.FieldInfo{Name: name, Type: "string"}
+		if name, tag, ok := strings.Cut(name, " "); ok {
+			field.Name, field.Tag = name, strings.Trim(tag, "`")
+		}
+		info.Fields = append(info.Fields, field)
+	}
+	return info
+}
+
+func TestStrategies(t *testing.T) {
+	tests := []struct {
+		name           string
+		strategy       Strategy
+		source, target string
+		want           bool
+	}{
+		{"exact", Exact, "UserID", "UserID", true},
+		{"exact differs in case", Exact, "UserID", "UserId", false},
+
+		{"case insensitive", CaseInsensitive, "UserId", "USERID", true},
+		{"case insensitive differs", CaseInsensitive, "UserId", "UserName", false},
+		{"case insensitive keeps underscores", CaseInsensitive, "user_id", "UserID", false},
+
+		{"initialisms", Initialisms, "UserId", "UserID", true},
+		{"initialisms in the middle", Initialisms, "HttpServerUrl", "HTTPServerURL", true},
+		{"initialisms leading run", Initialisms, "HTTPServerId", "HttpServerID", true},
+		{"initialisms only", Initialisms, "UserName", "Username", false},
+		{"initialisms unknown", Initialisms, "FooBar", "FOOBar", false},
+
+		{"json tag", JSONTag, "Name `json:\"user_name\"`", "UserName `json:\"user_name,omitempty\"`", true},
+		{"json tag differs", JSONTag, "Name `json:\"name\"`", "Name `json:\"user_name\"`", false},
+		{"json tag missing", JSONTag, "Name", "Name", false},
+		{"json tag skipped", JSONTag, "Name `json:\"-\"`", "Name `json:\"-\"`", false},
+		{"json tag without name", JSONTag, "Name `json:\",omitempty\"`", "Name `json:\",omitempty\"`", false},
+
+		{"snake camel", SnakeCamel, "user_id", "UserID", true},
+		{"snake camel both ways", SnakeCamel, "UserID", "user_id", true},
+		{"snake camel differs", SnakeCamel, "user_name", "UserID", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, target := structOf(tt.source).Fields[0], structOf(tt.target).Fields[0]
+			if got := tt.strategy(source, target); got != tt.want {
+				t.Errorf("%s, %s: got %v, want %v", tt.source, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategies []Strategy
+		source     []string
+		target     []string
+		want       map[string]string
+		err        string // empty for none
+	}{
+		{
+			name:   "defaults to exact",
+			source: []string{"ID", "Name", "UserId"},
+			target: []string{"ID", "Name", "UserID"},
+			want:   map[string]string{"ID": "ID", "Name": "Name"},
+		},
+		{
+			name:       "case insensitive",
+			strategies: []Strategy{CaseInsensitive},
+			source:     []string{"Userid", "Email"},
+			target:     []string{"UserID", "EMAIL"},
+			want:       map[string]string{"Userid": "UserID", "Email": "EMAIL"},
+		},
+		{
+			name:       "initialisms",
+			strategies: []Strategy{Exact, Initialisms},
+			source:     []string{"UserId", "ApiUrl", "Name"},
+			target:     []string{"UserID", "APIURL", "Name"},
+			want:       map[string]string{"UserId": "UserID", "ApiUrl": "APIURL", "Name": "Name"},
+		},
+		{
+			name:       "json tags",
+			strategies: []Strategy{JSONTag},
+			source:     []string{"Name `json:\"full_name\"`", "Mail `json:\"email\"`"},
+			target:     []string{"FullName `json:\"full_name\"`", "Email `json:\"email,omitempty\"`"},
+			want:       map[string]string{"Name": "FullName", "Mail": "Email"},
+		},
+		{
+			name:       "snake camel",
+			strategies: []Strategy{SnakeCamel},
+			source:     []string{"user_id", "created_at"},
+			target:     []string{"UserID", "CreatedAt"},
+			want:       map[string]string{"user_id": "UserID", "created_at": "CreatedAt"},
+		},
+		{
+			name:       "earlier strategies win",
+			strategies: []Strategy{Exact, CaseInsensitive},
+			source:     []string{"Name", "NAME"},
+			target:     []string{"Name"},
+			want:       map[string]string{"Name": "Name"},
+		},
+		{
+			name:       "later strategies only see unmatched fields",
+			strategies: []Strategy{Exact, CaseInsensitive},
+			source:     []string{"Id", "ID"},
+			target:     []string{"ID", "id"},
+			want:       map[string]string{"ID": "ID", "Id": "id"},
+		},
+		{
+			name:       "source collision",
+			strategies: []Strategy{CaseInsensitive},
+			source:     []string{"UserId", "UserID"},
+			target:     []string{"USERID"},
+			err:        "ambiguous field matches: USERID matches UserId, UserID",
+		},
+		{
+			name:       "target collision",
+			strategies: []Strategy{SnakeCamel},
+			source:     []string{"user_id"},
+			target:     []string{"UserID", "UserId"},
+			err:        "ambiguous field matches: user_id matches UserID, UserId",
+		},
+		{
+			name:       "ambiguous matches are all reported",
+			strategies: []Strategy{CaseInsensitive},
+			source:     []string{"Name", "name", "Id"},
+			target:     []string{"NAME", "ID", "iD"},
+			err:        "ambiguous field matches: Id matches ID, iD; NAME matches Name, name",
+		},
+		{
+			name:   "nothing matches",
+			source: []string{"A"},
+			target: []string{"B"},
+			want:   map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := &FieldMatcher{Strategies: tt.strategies}
+			got, err := matcher.MatchFields(structOf(tt.source...), structOf(tt.target...))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("MatchFields() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MatchFields() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
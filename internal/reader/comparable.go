@@ -0,0 +1,131 @@
+package reader
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	gotypes "go/types"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// resolveComparable records the named types written in a field type that support ==, see StructInfo.Comparable
+//
+// Pointed to, element and map value types are checked too, as generated comparisons descend into them
+func (r *Reader) resolveComparable(info *types.StructInfo, expr ast.Expr) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		r.resolveComparable(info, t.X)
+	case *ast.ArrayType:
+		r.resolveComparable(info, t.Elt)
+	case *ast.MapType:
+		r.resolveComparable(info, t.Value)
+	case *ast.ParenExpr:
+		r.resolveComparable(info, t.X)
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+		if ident, ok := t.(*ast.Ident); ok && (predeclared[ident.Name] || isTypeParam(ident.Name, info)) {
+			return
+		}
+		name := gotypes.ExprString(expr)
+		if _, ok := info.Comparable[name]; !ok {
+			info.Comparable[name] = r.comparable(info, name)
+		}
+	}
+}
+
+// comparable type checks a type written in owner's package, false if it isn't comparable, is an interface or
+// can't be checked (eg: it's unexported or refers to type parameters)
+func (r *Reader) comparable(owner *types.StructInfo, typeStr string) bool {
+	expr, err := parser.ParseExpr(typeStr)
+	if err != nil {
+		return false
+	}
+
+	// names declared in owner's package are qualified with an import of it
+	const own = "modelgenowner"
+	local := true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.SelectorExpr:
+			return false
+		case *ast.Ident:
+			if isTypeParam(t.Name, owner) {
+				local = false
+			} else if !predeclared[t.Name] {
+				t.Name = own + "." + t.Name
+			}
+		}
+		return true
+	})
+	if !local {
+		return false
+	}
+
+	qualifiers := make([]string, 0, len(owner.Imports))
+	for qualifier := range owner.Imports {
+		qualifiers = append(qualifiers, qualifier)
+	}
+	sort.Strings(qualifiers)
+
+	var src strings.Builder
+	src.WriteString("package modelgencheck\n\nimport (\n")
+	fmt.Fprintf(&src, "\t%s %s\n", own, strconv.Quote(owner.PackagePath))
+	for _, qualifier := range qualifiers {
+		fmt.Fprintf(&src, "\t%s %s\n", qualifier, strconv.Quote(owner.Imports[qualifier]))
+	}
+	fmt.Fprintf(&src, ")\n\nvar check %s\n", getTypeName(expr))
+
+	file, err := parser.ParseFile(r.fset, "modelgencheck.go", src.String(), 0)
+	if err != nil {
+		return false
+	}
+
+	// unused imports are reported as errors, the type is still checked
+	conf := gotypes.Config{Importer: r.typesImporter(owner.PackagePath), Error: func(error) {}}
+	pkg, _ := conf.Check("modelgencheck", r.fset, []*ast.File{file}, nil)
+	if pkg == nil {
+		return false
+	}
+	check := pkg.Scope().Lookup("check")
+	if check == nil || check.Type() == gotypes.Typ[gotypes.Invalid] {
+		return false
+	}
+	// interfaces compile with == but panic on values of types that aren't comparable
+	return gotypes.Comparable(check.Type()) && !gotypes.IsInterface(check.Type())
+}
+
+// typesImporter returns an importer reading the export data of pkgPath and its dependencies, built by go list
+func (r *Reader) typesImporter(pkgPath string) gotypes.Importer {
+	if r.exports == nil {
+		r.exports = make(map[string]string)
+	}
+	if _, ok := r.exports[pkgPath]; !ok {
+		r.exports[pkgPath] = ""
+		cmd := exec.Command("go", "list", "-e", "-export", "-deps", "-f", "{{.ImportPath}} {{.Export}}", pkgPath)
+		if output, err := cmd.Output(); err == nil {
+			for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+				if path, export, ok := strings.Cut(line, " "); ok && export != "" {
+					r.exports[path] = export
+				}
+			}
+		}
+	}
+
+	if r.importer == nil {
+		r.importer = importer.ForCompiler(r.fset, "gc", func(path string) (io.ReadCloser, error) {
+			export := r.exports[path]
+			if export == "" {
+				return nil, fmt.Errorf("no export data for %s", path)
+			}
+			return os.Open(export)
+		})
+	}
+	return r.importer
+}
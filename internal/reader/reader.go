@@ -1,26 +1,46 @@
 package reader
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/parser"
+	"go/printer"
 	"go/token"
+	gotypes "go/types"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/matt0792/modelgen/internal/types"
 )
 
 type Reader struct {
-	fset    *token.FileSet
-	pkgPath string
+	fset     *token.FileSet
+	pkgPath  string
+	packages map[string]*parsedPackage // parsed packages by import path
+	exports  map[string]string         // export data files by import path, see typesImporter
+	importer gotypes.Importer
+}
+
+// parsedPackage is the syntax of a package, without its tests
+type parsedPackage struct {
+	files   []*ast.File
+	imports map[*ast.File]map[string]string // filled as needed, see importsOf
 }
 
 func NewReader(pkgPath string) *Reader {
 	return &Reader{
-		fset:    token.NewFileSet(),
-		pkgPath: pkgPath,
+		fset:     token.NewFileSet(),
+		pkgPath:  pkgPath,
+		packages: make(map[string]*parsedPackage),
 	}
 }
 
@@ -30,9 +50,155 @@ func (r *Reader) Read(structType interface{}) (*types.StructInfo, error) {
 		t = t.Elem()
 	}
 
-	pkgPath := t.PkgPath()
-	typeName := t.Name()
+	// instantiated generics are named with their type arguments, eg: "Page[github.com/x/api.User]"
+	if name, args, ok := splitTypeArgs(t.Name()); ok {
+		info, err := r.ReadNamed(t.PkgPath(), name)
+		if err != nil {
+			return nil, err
+		}
+		return r.instantiate(info, args)
+	}
+
+	return r.ReadNamed(t.PkgPath(), t.Name())
+}
+
+// ReadGeneric reads the declaration of a generic struct, ignoring the type arguments structType was instantiated with
+func (r *Reader) ReadGeneric(structType interface{}) (*types.StructInfo, error) {
+	t := reflect.TypeOf(structType)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	name, _, ok := splitTypeArgs(t.Name())
+	if !ok {
+		return nil, fmt.Errorf("%s is not a generic type", t.Name())
+	}
+	return r.ReadNamed(t.PkgPath(), name)
+}
+
+// ReadEnum reads a named basic type (eg: type Status string) and the constants declared with it, from a value of it
+//
+// The returned StructInfo only identifies the type, it has no fields
+func (r *Reader) ReadEnum(value interface{}) (*types.StructInfo, *types.EnumInfo, error) {
+	t := reflect.TypeOf(value)
+	if t == nil || t.PkgPath() == "" {
+		return nil, nil, fmt.Errorf("%v is not of a named type", value)
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+	default:
+		return nil, nil, fmt.Errorf("%s is not a named basic type", t)
+	}
+
+	return r.ReadNamedEnum(t.PkgPath(), t.Name())
+}
+
+// ReadNamedEnum reads a named basic type and its constants by its package path and type name, see ReadEnum
+func (r *Reader) ReadNamedEnum(pkgPath, name string) (*types.StructInfo, *types.EnumInfo, error) {
+	pkg, err := r.loadPackage(pkgPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// constants are evaluated by the type checker (eg: iota), other packages aren't needed for that
+	conf := gotypes.Config{
+		Importer:    importerFunc(func(path string) (*gotypes.Package, error) { return nil, fmt.Errorf("%s not loaded", path) }),
+		FakeImportC: true,
+		Error:       func(error) {},
+	}
+	checked, _ := conf.Check(pkgPath, r.fset, pkg.files, nil)
+	typeName, ok := checked.Scope().Lookup(name).(*gotypes.TypeName)
+	if !ok {
+		return nil, nil, fmt.Errorf("type %s not found", name)
+	}
+	basic, ok := typeName.Type().Underlying().(*gotypes.Basic)
+	if !ok || basic.Info()&gotypes.IsUntyped != 0 {
+		return nil, nil, fmt.Errorf("%s.%s is not a named basic type", checked.Name(), name)
+	}
+
+	var consts []*gotypes.Const
+	for _, name := range checked.Scope().Names() {
+		if c, ok := checked.Scope().Lookup(name).(*gotypes.Const); ok && gotypes.Identical(c.Type(), typeName.Type()) {
+			consts = append(consts, c)
+		}
+	}
+	// declaration order, files are parsed in name order
+	sort.Slice(consts, func(i, j int) bool {
+		return consts[i].Pos() < consts[j].Pos()
+	})
+
+	info := &types.StructInfo{
+		PackageName: checked.Name(),
+		PackagePath: pkgPath,
+		TypeName:    name,
+	}
+	enum := &types.EnumInfo{Underlying: basic.Name()}
+
+	docs := make(map[string][2]string) // const name -> doc, line comment
+	for _, file := range pkg.files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				// doc is attached to the decl unless declared in a group
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.Name != name {
+						continue
+					}
+					doc := spec.Doc
+					if doc == nil && len(genDecl.Specs) == 1 {
+						doc = genDecl.Doc
+					}
+					info.Doc = doc.Text()
+				case *ast.ValueSpec:
+					doc := spec.Doc
+					if doc == nil && len(genDecl.Specs) == 1 {
+						doc = genDecl.Doc
+					}
+					for _, name := range spec.Names {
+						docs[name.Name] = [2]string{doc.Text(), spec.Comment.Text()}
+					}
+				}
+			}
+		}
+	}
+
+	for _, c := range consts {
+		enum.Values = append(enum.Values, types.EnumValue{
+			Name:    c.Name(),
+			Value:   constLiteral(c.Val()),
+			Doc:     docs[c.Name()][0],
+			Comment: docs[c.Name()][1],
+		})
+	}
+
+	return info, enum, nil
+}
+
+type importerFunc func(path string) (*gotypes.Package, error)
 
+func (f importerFunc) Import(path string) (*gotypes.Package, error) {
+	return f(path)
+}
+
+// constLiteral renders a constant value as a Go literal
+func constLiteral(val constant.Value) string {
+	switch val.Kind() {
+	case constant.String:
+		return strconv.Quote(constant.StringVal(val))
+	case constant.Float:
+		f, _ := constant.Float64Val(val)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return val.ExactString()
+}
+
+// ReadNamed reads a struct by its package path and type name
+func (r *Reader) ReadNamed(pkgPath, typeName string) (*types.StructInfo, error) {
 	// parse source file to get ast info
 	info, err := r.parseStructFromSource(pkgPath, typeName)
 	if err != nil {
@@ -41,63 +207,427 @@ func (r *Reader) Read(structType interface{}) (*types.StructInfo, error) {
 
 	// Add the full package import path to the struct info
 	info.PackagePath = pkgPath
+	r.resolveFields(info)
 
 	return info, nil
 }
 
+// ReadFieldType reads the struct type of a field declared in owner, dereferencing pointers
+func (r *Reader) ReadFieldType(owner *types.StructInfo, field types.FieldInfo) (*types.StructInfo, error) {
+	expr, err := parser.ParseExpr(field.Type)
+	if err != nil {
+		return nil, fmt.Errorf("field %s has unsupported type %s", field.Name, field.Type)
+	}
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	// type arguments are resolved in owner's package, then rewritten for the generic struct's
+	var args []string
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		expr, args = t.X, []string{qualifyType(t.Index, owner)}
+	case *ast.IndexListExpr:
+		expr = t.X
+		for _, index := range t.Indices {
+			args = append(args, qualifyType(index, owner))
+		}
+	}
+
+	var pkgPath, typeName string
+	switch t := expr.(type) {
+	case *ast.Ident:
+		pkgPath, typeName = owner.PackagePath, t.Name
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			pkgPath, typeName = owner.Imports[x.Name], t.Sel.Name
+		}
+	}
+	if typeName == "" || (pkgPath == "" && owner.PackagePath != "") {
+		return nil, fmt.Errorf("field %s of type %s is not a named struct", field.Name, field.Type)
+	}
+
+	info, err := r.ReadNamed(pkgPath, typeName)
+	if err != nil || args == nil {
+		return info, err
+	}
+	return r.instantiate(info, args)
+}
+
+// instantiate substitutes the type parameters of a generic struct with type arguments
+//
+// args are written with full import paths, as in reflect type names, and rewritten as seen from the struct's package
+func (r *Reader) instantiate(info *types.StructInfo, args []string) (*types.StructInfo, error) {
+	if len(args) != len(info.TypeParams) {
+		return nil, fmt.Errorf("%s has %d type parameters, got %d type arguments", info.TypeName, len(info.TypeParams), len(args))
+	}
+
+	inst := *info
+	inst.TypeParams = nil
+	inst.Imports = make(map[string]string, len(info.Imports))
+	for name, pkgPath := range info.Imports {
+		inst.Imports[name] = pkgPath
+	}
+
+	subst := make(map[string]string, len(args))
+	for i, arg := range args {
+		arg = r.localizeType(arg, &inst)
+		inst.TypeArgs = append(inst.TypeArgs, arg)
+		subst[info.TypeParams[i].Name] = arg
+	}
+
+	inst.Fields = make([]types.FieldInfo, len(info.Fields))
+	for i, field := range info.Fields {
+		// types the reader couldn't fully describe (eg: "func(...)") are left as they are
+		if expr, err := parser.ParseExpr(field.Type); err == nil {
+			ast.Inspect(expr, func(n ast.Node) bool {
+				switch t := n.(type) {
+				case *ast.SelectorExpr:
+					// qualified names never refer to type parameters
+					return false
+				case *ast.Ident:
+					if arg, ok := subst[t.Name]; ok {
+						t.Name = arg
+					}
+				}
+				return true
+			})
+			field.Type = getTypeName(expr)
+		}
+
+		if expr, err := parser.ParseExpr(field.Type); err == nil {
+			field.IsPointer = r.isPointer(expr)
+			field.IsSlice = r.isSlice(expr)
+			field.IsNested = r.isStructType(expr)
+		}
+		inst.Fields[i] = field
+	}
+	r.resolveFields(&inst)
+
+	return &inst, nil
+}
+
+var qualifiedName = regexp.MustCompile(`((?:[\w.~-]+/)*[\w.~-]+)\.([A-Za-z_]\w*)`)
+
+// localizeType rewrites a type written with full import paths as seen from info's package,
+// adding imports to info as needed
+func (r *Reader) localizeType(typeStr string, info *types.StructInfo) string {
+	return qualifiedName.ReplaceAllStringFunc(typeStr, func(match string) string {
+		parts := qualifiedName.FindStringSubmatch(match)
+		pkgPath, name := parts[1], parts[2]
+		if pkgPath == info.PackagePath {
+			return name
+		}
+		return r.importName(pkgPath, info) + "." + name
+	})
+}
+
+// importName returns the qualifier info's field types use for a package, adding an import if there's none
+func (r *Reader) importName(pkgPath string, info *types.StructInfo) string {
+	for name, importPath := range info.Imports {
+		if importPath == pkgPath {
+			return name
+		}
+	}
+
+	base := r.pkgNames([]string{pkgPath})[pkgPath]
+	name := base
+	for i := 2; info.Imports[name] != ""; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	info.Imports[name] = pkgPath
+	return name
+}
+
 func (r *Reader) parseStructFromSource(pkgPath, typeName string) (*types.StructInfo, error) {
 	// 1. find package dir from packagePath
 	// 2. parse all .go files in package
 	// 3. find matching struct declartion
 	// 4. extract field info and nested structs
 
+	pkg, err := r.loadPackage(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range pkg.files {
+		info := r.findStructInFile(pkg, file, typeName)
+		if info != nil {
+			return info, nil
+		}
+	}
+
+	return nil, fmt.Errorf("struct %s not found", typeName)
+}
+
+// loadPackage parses the files of a package, once per reader
+func (r *Reader) loadPackage(pkgPath string) (*parsedPackage, error) {
+	if pkg, ok := r.packages[pkgPath]; ok {
+		return pkg, nil
+	}
+
 	// Convert package path to directory path using go list
 	dirPath, err := r.pkgPathToDir(pkgPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find directory for package %s: %w", pkgPath, err)
 	}
 
-	pkgs, err := parser.ParseDir(r.fset, dirPath, nil, parser.ParseComments)
+	notTest := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}
+	pkgs, err := parser.ParseDir(r.fset, dirPath, notTest, parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, pkg := range pkgs {
-		for _, file := range pkg.Files {
-			info := r.findStructInFile(file, typeName)
-			if info != nil {
-				return info, nil
+	// files excluded by build constraints may declare another package (eg: "package main" generators),
+	// the package is the one with the most files
+	pkg := &parsedPackage{imports: make(map[*ast.File]map[string]string)}
+	var names []string
+	for name := range pkgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if len(pkgs[name].Files) <= len(pkg.files) {
+			continue
+		}
+		pkg.files = pkg.files[:0]
+		for _, file := range pkgs[name].Files {
+			pkg.files = append(pkg.files, file)
+		}
+	}
+	sort.Slice(pkg.files, func(i, j int) bool {
+		return r.fset.Position(pkg.files[i].Pos()).Filename < r.fset.Position(pkg.files[j].Pos()).Filename
+	})
+
+	r.packages[pkgPath] = pkg
+	return pkg, nil
+}
+
+// importsOf returns the imports of a file of pkg, see fileImports
+func (r *Reader) importsOf(pkg *parsedPackage, file *ast.File) map[string]string {
+	imports, ok := pkg.imports[file]
+	if !ok {
+		imports = r.fileImports(file)
+		pkg.imports[file] = imports
+	}
+	return imports
+}
+
+// resolveFields records the underlying type of named field types, so named basic types
+// (eg: type Status string) aren't taken for nested structs
+func (r *Reader) resolveFields(info *types.StructInfo) {
+	info.Comparable = make(map[string]bool)
+	for i := range info.Fields {
+		field := &info.Fields[i]
+		expr, err := parser.ParseExpr(field.Type)
+		if err != nil {
+			continue
+		}
+		r.resolveComparable(info, expr)
+
+		field.Underlying = r.underlyingType(expr, info, 0)
+		if !field.IsNested {
+			continue
+		}
+
+		// the element type decides, eg: []*Status
+		elem := expr
+		for {
+			if star, ok := elem.(*ast.StarExpr); ok {
+				elem = star.X
+			} else if array, ok := elem.(*ast.ArrayType); ok {
+				elem = array.Elt
+			} else {
+				break
 			}
 		}
+		if ident, ok := elem.(*ast.Ident); ok && isTypeParam(ident.Name, info) {
+			field.IsNested = false
+			continue
+		}
+		if underlying := r.underlyingType(elem, info, 0); underlying != "" && underlying != "struct" {
+			field.IsNested = false
+		}
 	}
+}
 
-	return nil, fmt.Errorf("struct %s not found", typeName)
+// Underlying describes the underlying type of a type written in owner's package, see FieldInfo.Underlying
+func (r *Reader) Underlying(owner *types.StructInfo, typeStr string) string {
+	expr, err := parser.ParseExpr(typeStr)
+	if err != nil {
+		return ""
+	}
+	return r.underlyingType(expr, owner, 0)
+}
+
+// underlyingType describes the underlying type of a named type written in owner's package: a predeclared type name
+// (eg: "string") or "struct", "pointer", "slice", "array", "map", "func", "chan", "interface"
+//
+// Returns "" for unnamed types and types that can't be found
+func (r *Reader) underlyingType(expr ast.Expr, owner *types.StructInfo, depth int) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if predeclared[t.Name] || isTypeParam(t.Name, owner) {
+			return ""
+		}
+		return r.namedUnderlying(owner.PackagePath, t.Name, depth)
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			if pkgPath, ok := owner.Imports[x.Name]; ok {
+				return r.namedUnderlying(pkgPath, t.Sel.Name, depth)
+			}
+		}
+	case *ast.IndexExpr:
+		return r.underlyingType(t.X, owner, depth)
+	case *ast.IndexListExpr:
+		return r.underlyingType(t.X, owner, depth)
+	}
+	return ""
 }
 
-func (a *Reader) findStructInFile(file *ast.File, typeName string) *types.StructInfo {
-	var result *types.StructInfo
+// namedUnderlying describes the underlying type of a type declared in a package, see underlyingType
+func (r *Reader) namedUnderlying(pkgPath, typeName string, depth int) string {
+	// named types declared from one another, eg: type Level Priority
+	if pkgPath == "" || depth > 8 {
+		return ""
+	}
+	pkg, err := r.loadPackage(pkgPath)
+	if err != nil {
+		return ""
+	}
 
-	ast.Inspect(file, func(n ast.Node) bool {
-		typeSpec, ok := n.(*ast.TypeSpec)
-		if !ok || typeSpec.Name.Name != typeName {
-			return true
+	for _, file := range pkg.files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.Name != typeName {
+					continue
+				}
+
+				switch t := typeSpec.Type.(type) {
+				case *ast.Ident:
+					if predeclared[t.Name] {
+						if t.Name == "error" || t.Name == "any" {
+							return "interface"
+						}
+						return t.Name
+					}
+				case *ast.StructType:
+					return "struct"
+				case *ast.StarExpr:
+					return "pointer"
+				case *ast.ArrayType:
+					if t.Len == nil {
+						return "slice"
+					}
+					return "array"
+				case *ast.MapType:
+					return "map"
+				case *ast.FuncType:
+					return "func"
+				case *ast.ChanType:
+					return "chan"
+				case *ast.InterfaceType:
+					return "interface"
+				}
+
+				owner := &types.StructInfo{PackagePath: pkgPath, Imports: r.importsOf(pkg, file)}
+				return r.underlyingType(typeSpec.Type, owner, depth+1)
+			}
 		}
+	}
+	return ""
+}
 
-		structType, ok := typeSpec.Type.(*ast.StructType)
-		if !ok {
+func isTypeParam(name string, info *types.StructInfo) bool {
+	for _, param := range info.TypeParams {
+		if param.Name == name {
 			return true
 		}
+	}
+	return false
+}
 
-		result = &types.StructInfo{
-			PackageName: file.Name.Name,
-			TypeName:    typeName,
-			Fields:      a.extractFields(structType),
+func (a *Reader) findStructInFile(pkg *parsedPackage, file *ast.File, typeName string) *types.StructInfo {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
 		}
 
-		return false
-	})
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if typeSpec.Name.Name != typeName {
+				continue
+			}
+
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			// doc is attached to the decl unless declared in a group: type ( ... )
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+
+			var params []types.TypeParam
+			if typeSpec.TypeParams != nil {
+				for _, field := range typeSpec.TypeParams.List {
+					for _, name := range field.Names {
+						params = append(params, types.TypeParam{Name: name.Name, Constraint: printType(field.Type)})
+					}
+				}
+			}
+
+			return &types.StructInfo{
+				PackageName: file.Name.Name,
+				TypeName:    typeName,
+				Doc:         doc.Text(),
+				Fields:      a.extractFields(structType),
+				Imports:     a.importsOf(pkg, file),
+				TypeParams:  params,
+			}
+		}
+	}
+
+	return nil
+}
+
+// fileImports maps the package qualifiers used in a file to their import paths
+func (r *Reader) fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+
+	var unnamed []string
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		if spec.Name == nil {
+			unnamed = append(unnamed, importPath)
+			continue
+		}
+
+		// blank and dot imports can't qualify a field type
+		if spec.Name.Name != "_" && spec.Name.Name != "." {
+			imports[spec.Name.Name] = importPath
+		}
+	}
 
-	return result
+	for importPath, name := range r.pkgNames(unnamed) {
+		imports[name] = importPath
+	}
+
+	return imports
 }
 
 func (r *Reader) extractFields(structType *ast.StructType) []types.FieldInfo {
@@ -106,8 +636,14 @@ func (r *Reader) extractFields(structType *ast.StructType) []types.FieldInfo {
 	for _, field := range structType.Fields.List {
 		for _, name := range field.Names {
 			fieldInfo := types.FieldInfo{
-				Name: name.Name,
-				Type: r.exprToString(field.Type),
+				Name:    name.Name,
+				Type:    r.exprToString(field.Type),
+				Doc:     field.Doc.Text(),
+				Comment: field.Comment.Text(),
+			}
+
+			if field.Tag != nil {
+				fieldInfo.Tag, _ = strconv.Unquote(field.Tag.Value)
 			}
 
 			// type characteristics
@@ -166,6 +702,11 @@ func (r *Reader) isStructType(expr ast.Expr) bool {
 	case *ast.StarExpr:
 		// pointer - check the underlying type
 		return r.isStructType(t.X)
+	case *ast.IndexExpr:
+		// instantiated generic - check the generic type
+		return r.isStructType(t.X)
+	case *ast.IndexListExpr:
+		return r.isStructType(t.X)
 	case *ast.ArrayType:
 		// slice - check the element type
 		return r.isStructType(t.Elt)
@@ -184,6 +725,62 @@ func (r *Reader) pkgPathToDir(pkgPath string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// pkgNames looks up the declared package names for a set of import paths
+func (r *Reader) pkgNames(pkgPaths []string) map[string]string {
+	names := make(map[string]string, len(pkgPaths))
+	if len(pkgPaths) == 0 {
+		return names
+	}
+
+	args := append([]string{"list", "-e", "-f", "{{.ImportPath}} {{.Name}}"}, pkgPaths...)
+	if output, err := exec.Command("go", args...).Output(); err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			if pkgPath, name, ok := strings.Cut(line, " "); ok && name != "" {
+				names[pkgPath] = name
+			}
+		}
+	}
+
+	// fall back to the conventional name for anything go list couldn't resolve
+	for _, pkgPath := range pkgPaths {
+		if _, ok := names[pkgPath]; !ok {
+			names[pkgPath] = guessPkgName(pkgPath)
+		}
+	}
+
+	return names
+}
+
+// DirToPkgPath converts a directory to the import path it has (or will have) within its module
+//
+// The directory doesn't need to exist yet, the path is derived from the nearest go.mod above it
+func (r *Reader) DirToPkgPath(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for modDir := absDir; ; modDir = filepath.Dir(modDir) {
+		data, err := os.ReadFile(filepath.Join(modDir, "go.mod"))
+		if err == nil {
+			modPath := parseModulePath(data)
+			if modPath == "" {
+				return "", fmt.Errorf("no module directive in %s", filepath.Join(modDir, "go.mod"))
+			}
+
+			rel, err := filepath.Rel(modDir, absDir)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(modPath, filepath.ToSlash(rel)), nil
+		}
+
+		if filepath.Dir(modDir) == modDir {
+			return "", fmt.Errorf("no go.mod found for %s", dir)
+		}
+	}
+}
+
 // --- Helpers ---
 
 func getStructType(expr ast.Expr) (*ast.StructType, bool) {
@@ -197,6 +794,100 @@ func getStructType(expr ast.Expr) (*ast.StructType, bool) {
 	}
 }
 
+// predeclared types never refer to a package
+var predeclared = map[string]bool{
+	"bool": true, "string": true, "error": true, "any": true, "comparable": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+	"byte": true, "rune": true,
+}
+
+// splitTypeArgs splits a reflect type name into its name and type arguments, eg: "Pair[int,string]" -> "Pair", [int string]
+func splitTypeArgs(typeName string) (string, []string, bool) {
+	name, rest, ok := strings.Cut(typeName, "[")
+	if !ok || !strings.HasSuffix(rest, "]") {
+		return typeName, nil, false
+	}
+	rest = strings.TrimSuffix(rest, "]")
+
+	var args []string
+	depth, start := 0, 0
+	for i, c := range rest {
+		switch c {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(rest[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	args = append(args, strings.TrimSpace(rest[start:]))
+
+	return name, args, true
+}
+
+// qualifyType renders a type written in owner's package with full import paths, as reflect names type arguments
+func qualifyType(expr ast.Expr, owner *types.StructInfo) string {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := t.X.(*ast.Ident); ok {
+				if pkgPath, ok := owner.Imports[x.Name]; ok {
+					x.Name = pkgPath
+				}
+			}
+			return false
+		case *ast.Ident:
+			if !predeclared[t.Name] && owner.PackagePath != "" {
+				t.Name = owner.PackagePath + "." + t.Name
+			}
+		}
+		return true
+	})
+	return printType(expr)
+}
+
+func printType(expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// guessPkgName derives a package name from its import path, eg: "github.com/x/yaml/v2" -> "yaml"
+func guessPkgName(pkgPath string) string {
+	name := path.Base(pkgPath)
+	if majorVersion.MatchString(name) && path.Dir(pkgPath) != "." {
+		name = path.Base(path.Dir(pkgPath))
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if idx := strings.Index(name, "."); idx != -1 {
+		name = name[:idx]
+	}
+	return strings.ReplaceAll(name, "-", "")
+}
+
+func parseModulePath(goMod []byte) string {
+	for _, line := range strings.Split(string(goMod), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "module") {
+			continue
+		}
+		modPath := strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		if idx := strings.Index(modPath, "//"); idx != -1 {
+			modPath = strings.TrimSpace(modPath[:idx])
+		}
+		return strings.Trim(modPath, "\"`")
+	}
+	return ""
+}
+
 func getTypeName(expr ast.Expr) string {
 	switch t := expr.(type) {
 	case *ast.Ident:
@@ -209,6 +900,14 @@ func getTypeName(expr ast.Expr) string {
 		return "map[" + getTypeName(t.Key) + "]" + getTypeName(t.Value)
 	case *ast.SelectorExpr:
 		return getTypeName(t.X) + "." + t.Sel.Name
+	case *ast.IndexExpr:
+		return getTypeName(t.X) + "[" + getTypeName(t.Index) + "]"
+	case *ast.IndexListExpr:
+		args := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			args[i] = getTypeName(index)
+		}
+		return getTypeName(t.X) + "[" + strings.Join(args, ", ") + "]"
 	case *ast.StructType:
 		return "struct{...}"
 	case *ast.InterfaceType:
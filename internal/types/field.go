@@ -1,10 +1,15 @@
 package types
 
 type FieldInfo struct {
-	Name      string
-	Type      string
-	IsOmitted bool
-	IsNested  bool
-	IsSlice   bool
-	IsPointer bool
+	Name       string
+	Type       string
+	IsOmitted  bool
+	IsNested   bool
+	IsSlice    bool
+	IsPointer  bool
+	Underlying string // named field types: predeclared underlying type (eg: "string" for api.Status) or "struct", "slice", "map"...
+	Tag        string // struct tag, without quotes
+	Source     string // target fields: path of the source field it's mapped from (eg: "Settings.Theme"), empty for target-only fields
+	Doc        string // doc comment text, without comment markers
+	Comment    string // line comment text, without comment markers
 }
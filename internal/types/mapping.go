@@ -3,6 +3,245 @@ package types
 type MappingConfig struct {
 	SourceType *StructInfo
 	TargetType *StructInfo
-	OmitFields map[string]bool
-	FieldMap   map[string]string
+
+	// package the mapping is generated into, same as the target's unless it's an existing struct
+	OutputPackage string
+	OutputPath    string
+
+	ExistingTarget bool   // target is a hand-written struct, only converters are generated for it
+	FromFuncName   string // package-level converter names, generated instead of methods when set
+	ToFuncName     string
+	ApplyFuncName  string
+	SliceFuncs     SliceFuncs // bulk converters, generated for every mapping
+
+	OmitFields map[string]bool   // source field paths
+	FieldMap   map[string]string // source field path -> target field name
+	Flatten    map[string]string // source field path -> prefix for the fields pulled up from it
+
+	// source info of every flattened struct, keyed by the path of the field holding it
+	NestedTypes map[string]*StructInfo
+
+	IgnoreFields map[string]bool // existing target fields without a source, left zero by From
+	ExtraFields  []ExtraField    // target-only fields
+	Groups       []FieldGroup    // source fields gathered into nested target structs
+
+	MirrorComments bool       // reference the mirrored source in generated docs
+	OmitPolicy     OmitPolicy // what To does with source fields the target doesn't carry
+
+	ApplyTo       bool // generate ApplyTo, writing mapped fields onto an existing source value
+	ApplySkipZero bool // ApplyTo leaves source fields alone when their target field is zero
+
+	Compare  bool // generate Equal and Diff on the target
+	Clone    bool // generate Clone on the target
+	DeepCopy bool // From and To copy slices, maps and pointers instead of sharing them with their input
+
+	// mirrors a named basic type and its constants instead of a struct, converted with FromFuncName and ToFuncName
+	Enum *EnumInfo
+
+	EnumMaps map[string]EnumMap // source field path -> table converting it to another enum type
+	Checked  bool               // generate FromE and ToE, failing on values the converters can't represent
+	Derefs   map[string]Deref   // source field path -> conversion of a pointer to the value it points to
+
+	Optionals    map[string]Optional // source field path -> conversion of a nullable field to OptionalType
+	OptionalType *OptionalType       // generic optional type nullable fields convert to, nil without Optionals
+
+	Conversions map[string]Conversion // source field path -> conversion to another numeric type, or between numbers and strings
+
+	Validate         bool              // generate Validate on the target, checking Rules and registered nested types
+	ValidateFuncName string            // package-level Validate, generated instead of the method when set
+	ValidateFromE    bool              // FromE returns the error of Validate, generated along with FromE
+	Rules            map[string][]Rule // source field path -> rules Validate checks the target field with
+
+	Accessors []string // unexported target fields with a getter and a setter, eg: Secret() and SetSecret(v) for secret
+
+	Warnings []string // problems Build worked around, eg: skipped validate tag rules, listed by the report
+}
+
+// OptionalType is a generic struct type holding a value that may be absent, eg: opt.Optional[T]
+type OptionalType struct {
+	Type TypeRef // the generic type, without type arguments
+	Some string  // package func wrapping a present value, eg: Some for func Some[T any](v T) Optional[T]
+	None string  // package func returning an absent value, eg: None for func None[T any]() Optional[T]; empty for the zero value
+	Get  string  // method returning the value and whether it's present, eg: Get for func (o Optional[T]) Get() (T, bool)
+}
+
+// Optional converts a nullable source field (a pointer or a database/sql null type) to an OptionalType
+type Optional struct {
+	Elem       string   // type of the value as written in the source package, eg: "string" for *string
+	ElemRef    *TypeRef // type of the value when the source package may not import it, eg: time.Time for sql.NullTime
+	Underlying string   // underlying type of Elem, see FieldInfo.Underlying
+	Value      string   // field holding the value of a database/sql null type, eg: "String" for sql.NullString; empty for pointers
+}
+
+// Deref converts a pointer source field to a value target field
+type Deref struct {
+	Default    string // Go literal From uses for nil pointers, empty for the zero value
+	Underlying string // underlying type of the pointed to type, see FieldInfo.Underlying
+	NilZero    bool   // To converts zero values back to nil instead of taking their address
+}
+
+// OmitPolicy decides what To does with source fields the target doesn't carry (omitted or not pulled up)
+type OmitPolicy int
+
+const (
+	OmitZero     OmitPolicy = iota // To leaves them zero
+	OmitFromBase                   // ToWith(base) copies them from base, instead of To
+	OmitNoTo                       // To isn't generated
+)
+
+// SliceFuncs names the bulk converters of a mapping, empty names aren't generated
+type SliceFuncs struct {
+	From    string // eg: AccountsFromAPI(src []api.Account) []Account
+	To      string // eg: AccountsToAPI(t []Account) []api.Account
+	PtrFrom string // eg: AccountPtrsFromAPI(src []*api.Account) []*Account
+	PtrTo   string // eg: AccountPtrsToAPI(t []*Account) []*api.Account
+}
+
+// Names returns every non-empty name
+func (f SliceFuncs) Names() []string {
+	var names []string
+	for _, name := range []string{f.From, f.To, f.PtrFrom, f.PtrTo} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// EnumInfo describes a named basic type (eg: type Status string) and the constants declared with it
+type EnumInfo struct {
+	Underlying string      // eg: "string"
+	Values     []EnumValue // in declaration order
+	Unknown    string      // target constant that values without a constant convert to
+}
+
+// EnumValue is a constant of an enum type
+type EnumValue struct {
+	Name       string // source constant, empty for a constant that only exists on the target
+	TargetName string // constant generated into the target package
+	Value      string // Go literal, eg: `"active"`, "1"
+	Doc        string
+	Comment    string
+}
+
+// EnumPolicy decides what converters do with values missing from an EnumMap
+type EnumPolicy int
+
+const (
+	EnumZero  EnumPolicy = iota // convert them to the default value
+	EnumPanic                   // panic
+	EnumError                   // convert them to the default value, FromE and ToE return an error
+)
+
+// ConvertPolicy decides what converters do with values a Conversion can't represent exactly
+type ConvertPolicy int
+
+const (
+	ConvertLossy ConvertPolicy = iota // convert them as Go's conversions do, strings that don't parse convert to 0
+	ConvertPanic                      // panic
+	ConvertError                      // convert them as with ConvertLossy, FromE and ToE return an error
+)
+
+// TimeFormat is how a time field is carried on the target
+type TimeFormat int
+
+const (
+	TimeKeep       TimeFormat = iota // as is
+	TimeUnix                         // time.Time as int64 seconds since the Unix epoch
+	TimeUnixMilli                    // time.Time as int64 milliseconds since the Unix epoch
+	TimeRFC3339                      // time.Time as an RFC 3339 string, with fractional seconds if any
+	TimeValue                        // *time.Time as time.Time
+	DurationMillis                   // time.Duration as int64 milliseconds
+)
+
+// Conversion converts a basic field to another basic type, numbers to numbers and numbers to and from strings,
+// or a time field to a number or a string
+type Conversion struct {
+	Field      string     // source field, for messages, eg: "api.Account.Count"
+	SourceKind string     // predeclared (underlying) type of the source field, eg: "int64"; its type for time fields, eg: "*time.Time"
+	TargetKind string     // predeclared (underlying) type of the target field, the type of generated ones, eg: "int32"
+	Time       TimeFormat // format of a time field, TimeKeep for basic fields
+	Policy     ConvertPolicy
+
+	FromFunc string // generated converters, eg: func accountCountFromAPI(v int64) (int32, bool)
+	ToFunc   string
+}
+
+// EnumMap converts a field to another enum type with a table of values
+type EnumMap struct {
+	Field      string  // source field, for messages, eg: "api.Task.Status"
+	Target     TypeRef // target field type
+	Underlying string  // underlying type of the target, eg: "int"
+
+	Values        []EnumPair
+	Default       ValueRef // target value unknown source values convert to
+	SourceDefault ValueRef // source value unknown target values convert back to
+	Policy        EnumPolicy
+
+	FromFunc string // generated converters, eg: func taskStatusFromAPI(v string) (domain.Status, bool)
+	ToFunc   string
+
+	// every constant of the source and target types, which the generated test expects in Values
+	SourceConsts []ValueRef
+	TargetConsts []ValueRef
+}
+
+// EnumPair is a source value and the target value it converts to
+type EnumPair struct {
+	Source ValueRef
+	Target ValueRef
+}
+
+// TypeRef is a named type declared in a package
+type TypeRef struct {
+	PackagePath string
+	PackageName string
+	Name        string
+}
+
+// ValueRef is a constant declared in a package, or a literal if Name is empty
+type ValueRef struct {
+	PackagePath string
+	PackageName string
+	Name        string
+	Literal     string // Go literal of the value, eg: `"ACTIVE"`
+}
+
+// ExtraField is a field that only exists on the target
+type ExtraField struct {
+	Name     string
+	Type     string // as written in the target package
+	Import   string // import path of the package Type refers to, if any
+	FromFunc string // populates the field in From: func(src *Source) Type
+	ToFunc   string // receives the field in To: func(value Type, dst *Source)
+}
+
+// FieldGroup gathers source fields into a nested struct generated with the target
+type FieldGroup struct {
+	Name         string      // target field holding the group
+	Type         *StructInfo // generated struct, its fields are mapped from the grouped source fields
+	SourceFields []string    // paths of the grouped source fields
+}
+
+// RuleKind is a check on a target field, see Rule
+type RuleKind int
+
+const (
+	RuleRequired  RuleKind = iota // the field isn't zero
+	RuleLen                       // the length of a string (in characters), slice or map is between Min and Max
+	RuleRange                     // a number is between Min and Max
+	RuleMatch                     // a string matches Pattern
+	RuleOneOf                     // the field is one of Values
+	RuleSize                      // RuleLen for strings, slices and maps, RuleRange for numbers, as validate tags' min and max
+	RuleOmitEmpty                 // zero values skip the other rules
+)
+
+// Rule is a check Validate runs on a target field, pointers are checked by the value they point to unless it's nil
+type Rule struct {
+	Kind     RuleKind
+	Min, Max string   // Go literals of the bounds of RuleLen and RuleRange, empty for no bound
+	Pattern  string   // regular expression of RuleMatch
+	Desc     string   // what Pattern matches, for messages, eg: "a valid email address"; empty to quote Pattern
+	Var      string   // package variable holding the compiled Pattern, eg: accountEmailPattern
+	Values   []string // Go literals of RuleOneOf, eg: `"admin"`
 }
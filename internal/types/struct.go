@@ -4,5 +4,21 @@ type StructInfo struct {
 	PackageName string // eg: "externalservice"
 	PackagePath string // eg: "github.com/matt0792/modelgen/externalservice"
 	TypeName    string
+	Doc         string // doc comment text, without comment markers
 	Fields      []FieldInfo
+	Imports     map[string]string // package qualifier used by field types -> import path
+
+	TypeParams []TypeParam // type parameters of a generic struct, field types refer to them by name
+	TypeArgs   []string    // type arguments of an instantiated generic struct, as written in its package
+
+	Comparable map[string]bool // named types written in field types (eg: "sql.NullString") -> whether they support ==
+
+	// structs written in field types (eg: "Plain", "sql.NullString") that aren't registered, read for deep copies
+	Structs map[string]*StructInfo
+}
+
+// TypeParam is a type parameter of a generic struct
+type TypeParam struct {
+	Name       string
+	Constraint string // as written in the struct's package, eg: "any", "~int | ~string"
 }
@@ -0,0 +1,129 @@
+package modelgen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// ConvertPolicy decides what converters do with values Convert can't represent exactly in the other type
+type ConvertPolicy = types.ConvertPolicy
+
+const (
+	ConvertLossy = types.ConvertLossy // convert them as Go does, eg: int64 overflowing int32 wraps around, strings that don't parse convert to 0 (default)
+	ConvertPanic = types.ConvertPanic // panic
+	ConvertError = types.ConvertError // convert them as ConvertLossy does, FromE and ToE are generated and fail on them instead
+)
+
+// conversion is a field type change as passed to Convert, resolved by Build
+type conversion struct {
+	field  string
+	typ    string
+	policy ConvertPolicy
+	time   TimeFormat // set by ConvertTime, with the time type of the field as kind
+	kind   string
+}
+
+// Convert changes the type of a numeric or string field on the target, eg: Convert("Count", "int32"), Convert("ID", "string")
+//
+// Numbers convert to other numeric types and to and from strings (with strconv). The generated converters check
+// that values survive the conversion both ways and handle those that don't according to policy.
+// Existing targets (see MapBetween) keep the type of their field, typ may be empty
+func (b *MappingBuilder) Convert(field, typ string, policy ...ConvertPolicy) *MappingBuilder {
+	var p ConvertPolicy
+	if len(policy) > 0 {
+		p = policy[0]
+	}
+
+	b.conversions = append(b.conversions, conversion{field: field, typ: typ, policy: p})
+	return b
+}
+
+// resolveConversions resolves the fields converted to another basic type, generated targets get that type
+func (b *MappingBuilder) resolveConversions(sourceInfo, targetInfo *types.StructInfo) error {
+	if len(b.conversions) == 0 {
+		return nil
+	}
+
+	b.config.Conversions = make(map[string]types.Conversion)
+	for _, c := range b.conversions {
+		conversion, err := b.resolveConversion(sourceInfo, targetInfo, c)
+		if err != nil {
+			return fmt.Errorf("%s: can't convert %s: %w", targetInfo.TypeName, c.field, err)
+		}
+		b.config.Conversions[c.field] = conversion
+		b.config.Checked = b.config.Checked || conversion.Policy == ConvertError
+	}
+	return nil
+}
+
+func (b *MappingBuilder) resolveConversion(sourceInfo, targetInfo *types.StructInfo, c conversion) (types.Conversion, error) {
+	var targetField *types.FieldInfo
+	for _, field := range b.targetFields(targetInfo) {
+		if field.Source == c.field {
+			targetField = field
+		}
+	}
+	if targetField == nil {
+		return types.Conversion{}, fmt.Errorf("no such mapped field")
+	}
+	if _, ok := b.config.EnumMaps[c.field]; ok {
+		return types.Conversion{}, fmt.Errorf("it's converted with MapEnum")
+	}
+	if _, ok := b.config.Derefs[c.field]; ok {
+		return types.Conversion{}, fmt.Errorf("it's dereferenced")
+	}
+	if _, ok := b.config.Optionals[c.field]; ok {
+		return types.Conversion{}, fmt.Errorf("it's optional")
+	}
+
+	_, field, ok := b.sourceField(sourceInfo, c.field)
+	if !ok {
+		return types.Conversion{}, fmt.Errorf("no such field")
+	}
+	sourceKind, targetKind := fieldKind(field), c.typ
+	if c.time != TimeKeep {
+		sourceKind, targetKind = c.kind, timeTarget(c.time)
+	}
+	if b.target != nil {
+		if c.typ == "" {
+			c.typ = targetKind
+		}
+		targetKind = fieldKind(*targetField)
+		if c.typ != "" && c.typ != targetKind {
+			return types.Conversion{}, fmt.Errorf("%s.%s is %s, not %s", targetInfo.TypeName, targetField.Name, targetField.Type, c.typ)
+		}
+	}
+
+	kinds := []string{sourceKind, targetKind}
+	if c.time != TimeKeep {
+		kinds = kinds[1:]
+	}
+	for _, kind := range kinds {
+		if class := valueClass(kind); class != "number" && class != "string" {
+			return types.Conversion{}, fmt.Errorf("%s is not a number or a string", kind)
+		}
+	}
+	if sourceKind == targetKind {
+		return types.Conversion{}, fmt.Errorf("it's %s already", targetKind)
+	}
+
+	name := strings.ToLower(targetInfo.TypeName[:1]) + targetInfo.TypeName[1:] + strings.ReplaceAll(c.field, ".", "")
+	pkgName := exportedName(sourceInfo.PackageName)
+	conversion := types.Conversion{
+		Field:      sourceInfo.PackageName + "." + sourceInfo.TypeName + "." + c.field,
+		SourceKind: sourceKind,
+		TargetKind: targetKind,
+		Time:       c.time,
+		Policy:     c.policy,
+		FromFunc:   name + "From" + pkgName,
+		ToFunc:     name + "To" + pkgName,
+	}
+
+	if b.target == nil {
+		targetField.Type = targetKind
+		targetField.Underlying = ""
+	}
+	return conversion, nil
+}
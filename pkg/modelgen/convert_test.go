@@ -0,0 +1,113 @@
+package modelgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matt0792/modelgen/pkg/modelgen/testdata/api"
+)
+
+// boundaryTest runs the generated converters of api.Numbers on values at the edge of their target types
+const boundaryTest = `package convert
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBoundaries(t *testing.T) {
+	fits := func(name string, got, want bool) {
+		t.Helper()
+		if got != want {
+			t.Errorf("%s: fits = %v, want %v", name, got, want)
+		}
+	}
+
+	_, ok := numbersSmallFromAPI(math.MaxInt8)
+	fits("MaxInt8 to int8", ok, true)
+	_, ok = numbersSmallFromAPI(math.MaxInt8 + 1)
+	fits("MaxInt8+1 to int8", ok, false)
+	_, ok = numbersSmallFromAPI(math.MinInt8 - 1)
+	fits("MinInt8-1 to int8", ok, false)
+
+	_, ok = numbersSignedFromAPI(-1)
+	fits("-1 to uint", ok, false)
+	_, ok = numbersSignedToAPI(math.MaxUint)
+	fits("MaxUint to int", ok, false)
+	_, ok = numbersUnsignedFromAPI(math.MaxInt64)
+	fits("MaxInt64 to int64", ok, true)
+	_, ok = numbersUnsignedFromAPI(math.MaxInt64 + 1)
+	fits("MaxInt64+1 to int64", ok, false)
+	_, ok = numbersUnsignedToAPI(-1)
+	fits("-1 to uint64", ok, false)
+
+	_, ok = numbersFloatFromAPI(1.5)
+	fits("1.5 to int", ok, false)
+	_, ok = numbersFloatFromAPI(-2)
+	fits("-2.0 to int", ok, true)
+	_, ok = numbersFloatFromAPI(math.NaN())
+	fits("NaN to int", ok, false)
+	_, ok = numbersFloatFromAPI(math.Inf(1))
+	fits("+Inf to int", ok, false)
+	_, ok = numbersFloatFromAPI(math.Inf(-1))
+	fits("-Inf to int", ok, false)
+	_, ok = numbersFloatFromAPI(1 << 63)
+	fits("2^63 to int", ok, false)
+	_, ok = numbersFloatFromAPI(-1 << 63)
+	fits("-2^63 to int", ok, true)
+	_, ok = numbersFloat32FromAPI(1 << 31)
+	fits("2^31 to int32", ok, false)
+	_, ok = numbersFloat32FromAPI(-1 << 31)
+	fits("-2^31 to int32", ok, true)
+	_, ok = numbersHugeFromAPI(1 << 64)
+	fits("2^64 to uint64", ok, false)
+	_, ok = numbersHugeFromAPI(-1)
+	fits("-1.0 to uint64", ok, false)
+
+	_, ok = numbersWideFromAPI(math.MaxFloat64)
+	fits("MaxFloat64 to float32", ok, false)
+	_, ok = numbersWideFromAPI(math.Inf(1))
+	fits("+Inf to float32", ok, true)
+	_, ok = numbersWideFromAPI(1.5)
+	fits("1.5 to float32", ok, true)
+
+	_, ok = numbersTextFromAPI("abc")
+	fits("\"abc\" to int", ok, false)
+	_, ok = numbersTextFromAPI("1.5")
+	fits("\"1.5\" to int", ok, false)
+	v, ok := numbersTextFromAPI("-42")
+	fits("\"-42\" to int", ok && v == -42, true)
+	s, ok := numbersTextToAPI(math.MinInt64)
+	fits("MinInt64 to string", ok && s == "-9223372036854775808", true)
+}
+`
+
+func TestConvertBoundaries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles generated code")
+	}
+
+	// the generated package imports testdata/api, so it's generated within the module
+	dir := filepath.Join("testdata", "convert")
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	gen := New("convert")
+	err := gen.Register(&api.Numbers{}).
+		Convert("Small", "int8").
+		Convert("Signed", "uint").
+		Convert("Unsigned", "int64").
+		Convert("Float", "int").
+		Convert("Float32", "int32").
+		Convert("Wide", "float32").
+		Convert("Huge", "uint64").
+		Convert("Text", "int").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if err := gen.Generate(dir); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	testGenerated(t, dir, boundaryTest)
+}
@@ -0,0 +1,140 @@
+package modelgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// DerefOptions configures how a pointer field is converted to a value, see Deref
+type DerefOptions struct {
+	// Default is the value From uses for nil pointers, the zero value if nil. Ignored by WithDeref
+	Default interface{}
+
+	// NilZero makes To convert zero values back to nil instead of a pointer to the zero value
+	NilZero bool
+}
+
+// WithDeref converts every pointer to a basic type (eg: *string, *int, *api.Status) to a value in mappings
+// registered afterwards, as Deref does per field
+//
+// Existing targets are only affected where their field isn't a pointer, KeepPointers opts fields out
+func (m *ModelGen) WithDeref(opts ...DerefOptions) *ModelGen {
+	var opt DerefOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	m.deref = &opt
+	return m
+}
+
+// Deref converts a pointer field (eg: *string) to a value on the target, From uses the default for nil pointers
+// and To takes the address of the value
+//
+// Pointers to registered types are converted as usual, before taking the address of the result in To
+func (b *MappingBuilder) Deref(field string, opts ...DerefOptions) *MappingBuilder {
+	var opt DerefOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if b.derefs == nil {
+		b.derefs = make(map[string]DerefOptions)
+	}
+	b.derefs[field] = opt
+	return b
+}
+
+// KeepPointers leaves pointer fields as they are despite WithDeref
+func (b *MappingBuilder) KeepPointers(fields ...string) *MappingBuilder {
+	if b.keepPointers == nil {
+		b.keepPointers = make(map[string]bool)
+	}
+	for _, field := range fields {
+		b.keepPointers[field] = true
+	}
+	return b
+}
+
+// resolveDerefs resolves the fields converted from pointers to values, explicit ones and those covered by WithDeref
+//
+// Generated targets get the pointed to type for these fields
+func (b *MappingBuilder) resolveDerefs(sourceInfo, targetInfo *types.StructInfo) error {
+	targetFields := b.targetFields(targetInfo)
+
+	derefs := make(map[string]DerefOptions)
+	if b.deref != nil {
+		for _, targetField := range targetFields {
+			path := targetField.Source
+			if path == "" || b.keepPointers[path] || (b.target != nil && targetField.IsPointer) {
+				continue
+			}
+			if _, ok := b.config.EnumMaps[path]; ok {
+				continue
+			}
+
+			owner, field, ok := b.sourceField(sourceInfo, path)
+			if !ok || !strings.HasPrefix(field.Type, "*") {
+				continue
+			}
+			elem := strings.TrimPrefix(field.Type, "*")
+			if valueClass(elem) != "" || valueClass(b.parent.reader.Underlying(owner, elem)) != "" {
+				derefs[path] = DerefOptions{NilZero: b.deref.NilZero}
+			}
+		}
+	}
+	for path, opt := range b.derefs {
+		derefs[path] = opt
+	}
+	if len(derefs) == 0 {
+		return nil
+	}
+
+	b.config.Derefs = make(map[string]types.Deref)
+	for path, opt := range derefs {
+		var targetField *types.FieldInfo
+		for _, field := range targetFields {
+			if field.Source == path {
+				targetField = field
+			}
+		}
+		if targetField == nil {
+			return fmt.Errorf("%s: can't deref %s, no such mapped field", targetInfo.TypeName, path)
+		}
+		if _, ok := b.config.EnumMaps[path]; ok {
+			return fmt.Errorf("%s: can't deref %s, it's converted with MapEnum", targetInfo.TypeName, path)
+		}
+
+		owner, field, ok := b.sourceField(sourceInfo, path)
+		if !ok || !strings.HasPrefix(field.Type, "*") {
+			return fmt.Errorf("%s: can't deref %s, it's not a pointer", targetInfo.TypeName, path)
+		}
+		elem := strings.TrimPrefix(field.Type, "*")
+
+		deref := types.Deref{
+			Underlying: b.parent.reader.Underlying(owner, elem),
+			NilZero:    opt.NilZero,
+		}
+		if opt.Default != nil {
+			kind := deref.Underlying
+			if kind == "" {
+				kind = elem
+			}
+			v := reflect.ValueOf(opt.Default)
+			if valueClass(kind) == "" || valueClass(v.Kind().String()) != valueClass(kind) {
+				return fmt.Errorf("%s: can't deref %s, default %v doesn't fit %s", targetInfo.TypeName, path, opt.Default, elem)
+			}
+			deref.Default = literal(v)
+		}
+		b.config.Derefs[path] = deref
+
+		if b.target == nil {
+			targetField.Type = elem
+			targetField.IsPointer = false
+		}
+	}
+	return nil
+}
@@ -0,0 +1,402 @@
+package modelgen
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// EnumBuilder configures the mirror of a named basic type, see RegisterEnum
+type EnumBuilder struct {
+	parent     *ModelGen
+	value      interface{}
+	targetName string    // (optional) override for the type name
+	unknown    string    // (optional) source constant values without a constant convert to
+	funcNames  FuncNames // (optional) overrides for converter names
+	config     types.MappingConfig
+}
+
+// RegisterEnum returns a fluent builder mirroring a named basic type (eg: type Status string) and the constants
+// declared with it, from any value of the type, eg: RegisterEnum(api.StatusActive)
+//
+// A local type with the same underlying type and constants is generated along with converters (eg: StatusFromAPI,
+// StatusToAPI), which struct fields of the type are converted with. Values without a constant convert to the
+// unknown constant, see WithUnknown
+func (m *ModelGen) RegisterEnum(value interface{}) *EnumBuilder {
+	return &EnumBuilder{
+		parent: m,
+		value:  value,
+		config: types.MappingConfig{
+			OutputPackage: m.targetPackage,
+			OutputPath:    m.targetPath,
+		},
+	}
+}
+
+// WithTargetName allows a custom type name, constants prefixed with the source type name are renamed along with it
+// (eg: StatusActive -> StateActive)
+func (b *EnumBuilder) WithTargetName(name string) *EnumBuilder {
+	b.targetName = name
+	return b
+}
+
+// WithUnknown names the source constant that values without a constant convert to, both ways
+//
+// Defaults to the constant holding the zero value, or a generated StatusUnknown constant if there's none
+func (b *EnumBuilder) WithUnknown(constName string) *EnumBuilder {
+	b.unknown = constName
+	return b
+}
+
+// WithMirrorComments adds a reference to the mirrored source type to its generated doc comment
+func (b *EnumBuilder) WithMirrorComments() *EnumBuilder {
+	b.config.MirrorComments = true
+	return b
+}
+
+// WithFuncNames renames the converters, Apply is ignored
+func (b *EnumBuilder) WithFuncNames(names FuncNames) *EnumBuilder {
+	b.funcNames = names
+	return b
+}
+
+// Build builds the enum with its converters
+func (b *EnumBuilder) Build() error {
+	sourceInfo, enum, err := b.parent.reader.ReadEnum(b.value)
+	if err != nil {
+		return err
+	}
+
+	targetTypeName := b.targetName
+	if targetTypeName == "" {
+		targetTypeName = sourceInfo.TypeName
+	}
+
+	for i := range enum.Values {
+		value := &enum.Values[i]
+		value.TargetName = value.Name
+		if targetTypeName != sourceInfo.TypeName && strings.HasPrefix(value.Name, sourceInfo.TypeName) {
+			value.TargetName = targetTypeName + strings.TrimPrefix(value.Name, sourceInfo.TypeName)
+			if strings.HasPrefix(value.Doc, value.Name+" ") {
+				value.Doc = value.TargetName + strings.TrimPrefix(value.Doc, value.Name)
+			}
+		}
+	}
+
+	if b.unknown != "" {
+		for _, value := range enum.Values {
+			if value.Name == b.unknown {
+				enum.Unknown = value.TargetName
+			}
+		}
+		if enum.Unknown == "" {
+			return fmt.Errorf("%s: can't convert unknown values to %s, no such constant", sourceInfo.TypeName, b.unknown)
+		}
+	} else {
+		zero := `""`
+		switch enum.Underlying {
+		case "string":
+		case "bool":
+			zero = "false"
+		default:
+			zero = "0"
+		}
+		for _, value := range enum.Values {
+			if value.Value == zero {
+				enum.Unknown = value.TargetName
+				break
+			}
+		}
+		if enum.Unknown == "" {
+			enum.Unknown = targetTypeName + "Unknown"
+			enum.Values = append([]types.EnumValue{{TargetName: enum.Unknown, Value: zero}}, enum.Values...)
+		}
+	}
+
+	b.config.SourceType = sourceInfo
+	b.config.TargetType = &types.StructInfo{
+		PackageName: b.parent.targetPackage,
+		PackagePath: b.parent.targetPath,
+		TypeName:    targetTypeName,
+	}
+	b.config.Enum = enum
+
+	pkgName := exportedName(sourceInfo.PackageName)
+	b.config.FromFuncName = targetTypeName + "From" + pkgName
+	if b.funcNames.From != "" {
+		b.config.FromFuncName = b.funcNames.From
+	}
+	b.config.ToFuncName = targetTypeName + "To" + pkgName
+	if b.funcNames.To != "" {
+		b.config.ToFuncName = b.funcNames.To
+	}
+
+	b.parent.configs = append(b.parent.configs, b.config)
+	return nil
+}
+
+// EnumPolicy decides what converters do with values missing from a MapEnum table
+type EnumPolicy = types.EnumPolicy
+
+const (
+	EnumZero  = types.EnumZero  // convert them to the default (default)
+	EnumPanic = types.EnumPanic // panic
+	EnumError = types.EnumError // convert them to the default, FromE and ToE are generated and fail on them instead
+)
+
+// EnumDefaults configures the values missing from a MapEnum table convert to, see MapEnum
+type EnumDefaults struct {
+	Target interface{} // target value unknown source values convert to, the zero value if nil
+	Source interface{} // source value unknown target values convert back to, the zero value if nil
+	Policy EnumPolicy
+}
+
+// enumMapping is a table as passed to MapEnum, resolved by Build
+type enumMapping struct {
+	field    string
+	values   map[interface{}]interface{}
+	defaults EnumDefaults
+}
+
+// MapEnum converts a field to another enum type with a table of source values to target constants,
+// eg: MapEnum("Status", map[interface{}]interface{}{"ACTIVE": domain.StatusActive, "PAUSED": domain.StatusInactive})
+//
+// The target field gets the type of the table's values, which must be a named basic type. Keys are literals or
+// constants of the source field's type, both sides are referenced by constant name where one holds the value.
+// Only the table's values are known, zero values included: list them to convert them without falling back
+// to the defaults (and failing with EnumPanic and EnumError), eg: "": domain.StatusUnknown.
+// A test checking that every constant of both types is in the table is generated along with the mappings
+func (b *MappingBuilder) MapEnum(field string, values map[interface{}]interface{}, defaults ...EnumDefaults) *MappingBuilder {
+	var opt EnumDefaults
+	if len(defaults) > 0 {
+		opt = defaults[0]
+	}
+
+	b.enumMaps = append(b.enumMaps, enumMapping{field: field, values: values, defaults: opt})
+	return b
+}
+
+// resolveEnumMaps resolves the MapEnum tables against the source fields they convert
+func (b *MappingBuilder) resolveEnumMaps(sourceInfo, targetInfo *types.StructInfo) error {
+	if len(b.enumMaps) == 0 {
+		return nil
+	}
+
+	b.config.EnumMaps = make(map[string]types.EnumMap)
+	for _, m := range b.enumMaps {
+		enumMap, err := b.resolveEnumMap(sourceInfo, targetInfo, m)
+		if err != nil {
+			return fmt.Errorf("%s: can't map enum %s: %w", targetInfo.TypeName, m.field, err)
+		}
+		b.config.EnumMaps[m.field] = enumMap
+		b.config.Checked = b.config.Checked || enumMap.Policy == EnumError
+	}
+	return nil
+}
+
+func (b *MappingBuilder) resolveEnumMap(sourceInfo, targetInfo *types.StructInfo, m enumMapping) (types.EnumMap, error) {
+	if !hasTargetField(targetInfo, m.field) && b.findGroup(m.field) == nil {
+		return types.EnumMap{}, fmt.Errorf("no such mapped field")
+	}
+	if len(m.values) == 0 {
+		return types.EnumMap{}, fmt.Errorf("empty table")
+	}
+
+	owner, field, ok := b.sourceField(sourceInfo, m.field)
+	if !ok {
+		return types.EnumMap{}, fmt.Errorf("no such field")
+	}
+
+	// source values are checked against the field's type, named or not
+	source := enumType{underlying: field.Underlying}
+	if field.Underlying == "" {
+		source.underlying = field.Type
+	}
+	if valueClass(source.underlying) == "" {
+		return types.EnumMap{}, fmt.Errorf("%s is not a basic type", field.Type)
+	}
+	if field.Underlying != "" {
+		pkgPath, typeName := owner.PackagePath, field.Type
+		if idx := strings.Index(typeName, "."); idx != -1 {
+			pkgPath, typeName = owner.Imports[typeName[:idx]], typeName[idx+1:]
+		}
+		info, enum, err := b.parent.reader.ReadNamedEnum(pkgPath, typeName)
+		if err != nil {
+			return types.EnumMap{}, err
+		}
+		source = newEnumType(info, enum)
+	}
+
+	var target enumType
+	var targetType reflect.Type
+	for _, value := range m.values {
+		if targetType == nil {
+			targetType = reflect.TypeOf(value)
+			info, enum, err := b.parent.reader.ReadEnum(value)
+			if err != nil {
+				return types.EnumMap{}, err
+			}
+			target = newEnumType(info, enum)
+		}
+		if reflect.TypeOf(value) != targetType {
+			return types.EnumMap{}, fmt.Errorf("values are of both %s and %T", targetType, value)
+		}
+	}
+
+	keys := make([]reflect.Value, 0, len(m.values))
+	for key := range m.values {
+		v := reflect.ValueOf(key)
+		if err := source.check(v); err != nil {
+			return types.EnumMap{}, err
+		}
+		keys = append(keys, v)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessValue(keys[i], keys[j])
+	})
+
+	enumMap := types.EnumMap{
+		Field:         sourceInfo.PackageName + "." + sourceInfo.TypeName + "." + m.field,
+		Target:        target.ref,
+		Underlying:    target.underlying,
+		Default:       target.value(zeroLiteral(target.underlying)),
+		SourceDefault: source.value(zeroLiteral(source.underlying)),
+		Policy:        m.defaults.Policy,
+		SourceConsts:  source.consts,
+		TargetConsts:  target.consts,
+	}
+	for _, key := range keys {
+		enumMap.Values = append(enumMap.Values, types.EnumPair{
+			Source: source.value(literal(key)),
+			Target: target.value(literal(reflect.ValueOf(m.values[key.Interface()]))),
+		})
+	}
+
+	if m.defaults.Target != nil {
+		if reflect.TypeOf(m.defaults.Target) != targetType {
+			return types.EnumMap{}, fmt.Errorf("default %v is not a %s", m.defaults.Target, targetType)
+		}
+		enumMap.Default = target.value(literal(reflect.ValueOf(m.defaults.Target)))
+	}
+	if m.defaults.Source != nil {
+		v := reflect.ValueOf(m.defaults.Source)
+		if err := source.check(v); err != nil {
+			return types.EnumMap{}, err
+		}
+		enumMap.SourceDefault = source.value(literal(v))
+	}
+
+	name := strings.ToLower(targetInfo.TypeName[:1]) + targetInfo.TypeName[1:] + strings.ReplaceAll(m.field, ".", "")
+	pkgName := exportedName(sourceInfo.PackageName)
+	enumMap.FromFunc = name + "From" + pkgName
+	enumMap.ToFunc = name + "To" + pkgName
+
+	return enumMap, nil
+}
+
+// enumType is a basic type, with the constants declared with it if it's named
+type enumType struct {
+	ref        types.TypeRef // empty for predeclared types
+	underlying string
+	consts     []types.ValueRef
+}
+
+func newEnumType(info *types.StructInfo, enum *types.EnumInfo) enumType {
+	t := enumType{
+		ref:        types.TypeRef{PackagePath: info.PackagePath, PackageName: info.PackageName, Name: info.TypeName},
+		underlying: enum.Underlying,
+	}
+	for _, value := range enum.Values {
+		// unexported constants can't be referenced from the generated package
+		if ast.IsExported(value.Name) {
+			t.consts = append(t.consts, types.ValueRef{
+				PackagePath: info.PackagePath,
+				PackageName: info.PackageName,
+				Name:        value.Name,
+				Literal:     value.Value,
+			})
+		}
+	}
+	return t
+}
+
+// value returns the first constant holding a literal, or the literal itself
+func (t enumType) value(literal string) types.ValueRef {
+	for _, c := range t.consts {
+		if c.Literal == literal {
+			return c
+		}
+	}
+	return types.ValueRef{Literal: literal}
+}
+
+// check verifies that v is a value of the type, or an untyped value assignable to it
+func (t enumType) check(v reflect.Value) error {
+	if !v.IsValid() {
+		return fmt.Errorf("nil is not a %s value", t.underlying)
+	}
+	if valueClass(v.Kind().String()) != valueClass(t.underlying) {
+		return fmt.Errorf("%v is of type %s, not %s", v, v.Type(), t.underlying)
+	}
+	if v.Type().PkgPath() != "" && (v.Type().PkgPath() != t.ref.PackagePath || v.Type().Name() != t.ref.Name) {
+		return fmt.Errorf("%v is a %s, the field is not", v, v.Type())
+	}
+	return nil
+}
+
+// valueClass groups basic types whose untyped literals are interchangeable, empty for other types
+func valueClass(kind string) string {
+	switch kind {
+	case "string", "bool":
+		return kind
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "byte", "rune":
+		return "number"
+	}
+	return ""
+}
+
+// literal renders a basic value as a Go literal, formatted like the constants read from source
+func literal(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	}
+	return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+}
+
+func zeroLiteral(underlying string) string {
+	switch valueClass(underlying) {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	}
+	return "0"
+}
+
+// lessValue orders basic values of the same type
+func lessValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	}
+	return a.Float() < b.Float()
+}
@@ -3,15 +3,19 @@ package modelgen
 import (
 	"bytes"
 	"fmt"
+	"go/ast"
 	"go/format"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 	"unicode"
 
 	"github.com/matt0792/modelgen/internal/generator"
+	"github.com/matt0792/modelgen/internal/mapper"
 	"github.com/matt0792/modelgen/internal/reader"
 	"github.com/matt0792/modelgen/internal/types"
 	"github.com/matt0792/modelgen/internal/util"
@@ -22,6 +26,14 @@ type ModelGen struct {
 	generator     *generator.Generator
 	configs       []types.MappingConfig
 	targetPackage string
+	targetPath    string // import path of the generated package
+	deepCopy      bool
+	style         Style
+	deref         *DerefOptions // policy for pointers to basic types, see WithDeref
+	optional      interface{}   // instantiation of the generic type nullable fields convert to, see WithOptional
+	optionalNames OptionalNames
+	timeFormats   *TimeFormats     // formats of time fields, see WithTimeFormats
+	unexported    UnexportedPolicy // what mappings do with unexported fields, see WithUnexported
 }
 
 func New(targetPackage string) *ModelGen {
@@ -32,21 +44,75 @@ func New(targetPackage string) *ModelGen {
 	}
 }
 
+// WithTargetPath sets the import path of the generated package
+//
+// Inferred from the output dir and its go.mod if not set
+func (m *ModelGen) WithTargetPath(importPath string) *ModelGen {
+	m.targetPath = importPath
+	return m
+}
+
+// Style is how converters are generated, see WithStyle
+type Style int
+
+const (
+	StyleMethods Style = iota // From and To methods on the target, eg: (&Account{}).From(&src), account.To()
+	StyleFuncs                // package-level functions, eg: AccountFromAPI(&src), AccountToAPI(&account)
+)
+
+// WithStyle sets how converters are generated for every mapping registered afterwards, StyleMethods by default
+//
+// Existing targets (see MapBetween) always use StyleFuncs
+func (m *ModelGen) WithStyle(style Style) *ModelGen {
+	m.style = style
+	return m
+}
+
+// WithDeepCopy makes every From and To copy slices, maps and pointers instead of sharing them with their input,
+// so mutating a local model never mutates the external value it came from (or the other way around)
+//
+// Structs are copied field by field, interfaces and unexported fields of other packages (eg: time.Time's) are
+// copied as is
+func (m *ModelGen) WithDeepCopy() *ModelGen {
+	m.deepCopy = true
+	return m
+}
+
 // Register returns a fluent builder
 //
 // Source represents the external model to generate local mappings to/from
 func (m *ModelGen) Register(source interface{}) *MappingBuilder {
 	return &MappingBuilder{
-		parent:     m,
-		source:     source,
-		targetName: "", // derive from source if not set
+		parent:      m,
+		source:      source,
+		targetName:  "", // derive from source if not set
+		style:       m.style,
+		deref:       m.deref,
+		timeFormats: m.timeFormats,
+		unexported:  m.unexported,
 		config: types.MappingConfig{
-			OmitFields: make(map[string]bool),
-			FieldMap:   make(map[string]string),
+			OutputPackage: m.targetPackage,
+			OutputPath:    m.targetPath,
+			OmitFields:    make(map[string]bool),
+			FieldMap:      make(map[string]string),
+			Flatten:       make(map[string]string),
+			NestedTypes:   make(map[string]*types.StructInfo),
+			IgnoreFields:  make(map[string]bool),
 		},
 	}
 }
 
+// MapBetween returns a fluent builder mapping the source onto an existing target struct
+//
+// Fields are matched by name (see MatchWith), only converter functions are generated (eg: AccountFromAPI, AccountToAPI,
+// ApplyAccountToAPI).
+// Build fails on fields that don't match unless they're omitted (source) or ignored (target)
+func (m *ModelGen) MapBetween(source, target interface{}) *MappingBuilder {
+	b := m.Register(source)
+	b.target = target
+	return b
+}
+
 // Map registers and builds mappings for all fields in the source model, panics on err
 //
 // For more control/custom mappings, use Register()
@@ -57,13 +123,52 @@ func (m *ModelGen) Map(source interface{}) {
 }
 
 type MappingBuilder struct {
-	parent     *ModelGen
-	source     interface{}
-	targetName string // (optional) override for struct name
-	config     types.MappingConfig
+	parent       *ModelGen
+	source       interface{}
+	target       interface{}               // (optional) existing target struct, see MapBetween
+	targetName   string                    // (optional) override for struct name
+	strategies   []mapper.Strategy         // (optional) field matching for MapBetween
+	style        Style                     // converter style, defaults to the parent's
+	funcNames    FuncNames                 // (optional) overrides for package-level converter names
+	sliceFuncs   SliceFuncNames            // (optional) overrides for bulk converter names
+	generic      bool                      // generate a generic target from the source's declaration, see Generic
+	enumMaps     []enumMapping             // (optional) tables converting fields to other enum types, see MapEnum
+	deref        *DerefOptions             // policy for pointers to basic types, defaults to the parent's
+	derefs       map[string]DerefOptions   // (optional) pointer fields converted to values, see Deref
+	keepPointers map[string]bool           // (optional) pointer fields WithDeref leaves alone
+	optionals    []string                  // (optional) nullable fields converted to the optional type, see Optional
+	optionalAll  bool                      // every nullable field is converted to the optional type
+	conversions  []conversion              // (optional) fields converted to another basic type, see Convert
+	timeFormats  *TimeFormats              // formats of time fields, defaults to the parent's
+	times        map[string]timeConversion // (optional) time fields carried as numbers or strings, see ConvertTime
+	rules        map[string][]Rule         // (optional) rules Validate checks target fields with, see Validate
+	validateTag  string                    // (optional) struct tag of source fields translated into rules, see ValidateOptions
+	unexported   UnexportedPolicy          // what to do with unexported fields, defaults to the parent's
+	config       types.MappingConfig
+}
+
+// MatchStrategy decides whether a source and target field match, see MatchWith
+type MatchStrategy = mapper.Strategy
+
+// Field matching strategies for MapBetween
+var (
+	MatchExact           = mapper.Exact           // identical names (default)
+	MatchCaseInsensitive = mapper.CaseInsensitive // eg: Username -> UserName
+	MatchInitialisms     = mapper.Initialisms     // eg: UserId -> UserID, AvatarUrl -> AvatarURL
+	MatchJSONTag         = mapper.JSONTag         // same `json:"name"` tag
+	MatchSnakeCamel      = mapper.SnakeCamel      // eg: user_name -> UserName
+)
+
+// MatchFunc creates a strategy from a function matching field names
+func MatchFunc(match func(sourceField, targetField string) bool) MatchStrategy {
+	return func(source, target types.FieldInfo) bool {
+		return match(source.Name, target.Name)
+	}
 }
 
 // Omit skips mapping for the specified field
+//
+// Fields of flattened structs are omitted by path, eg: "Settings.PrivateField"
 func (b *MappingBuilder) Omit(fields ...string) *MappingBuilder {
 	for _, field := range fields {
 		b.config.OmitFields[field] = true
@@ -72,11 +177,93 @@ func (b *MappingBuilder) Omit(fields ...string) *MappingBuilder {
 }
 
 // MapField configures a custom mapping
+//
+// sourceField can be a path to a nested field (eg: "Settings.Theme") to pull it up into the target,
+// the nested struct is then no longer mapped as a whole (see Flatten)
 func (b *MappingBuilder) MapField(sourceField, targetField string) *MappingBuilder {
 	b.config.FieldMap[sourceField] = targetField
 	return b
 }
 
+// Flatten pulls all fields of a nested struct up into the target, eg: Settings.Theme -> Theme
+//
+// Pulled up fields are named with the optional prefix, eg: Flatten("Settings", "Settings") -> SettingsTheme.
+// From leaves them zero when an intermediate pointer is nil, To rebuilds the nested struct
+func (b *MappingBuilder) Flatten(field string, prefix ...string) *MappingBuilder {
+	b.config.Flatten[field] = strings.Join(prefix, "")
+	return b
+}
+
+// Group gathers source fields into a nested struct generated alongside the target,
+// eg: Group("Address", "Address", "AddressLine1", "AddressCity") -> Address.Line1, Address.City
+//
+// Grouped fields drop the field name as a prefix when present, MapField renames them within the group.
+// From populates the nested struct, To spreads it back out
+func (b *MappingBuilder) Group(field, typeName string, sourceFields ...string) *MappingBuilder {
+	b.config.Groups = append(b.config.Groups, types.FieldGroup{
+		Name: field,
+		Type: &types.StructInfo{
+			PackageName: b.parent.targetPackage,
+			PackagePath: b.parent.targetPath,
+			TypeName:    typeName,
+		},
+		SourceFields: sourceFields,
+	})
+	return b
+}
+
+// FieldOptions configures a target-only field, see AddField
+type FieldOptions struct {
+	// Import is the import path of the package the field type refers to, eg: "time" for "time.Time"
+	Import string
+
+	// From names a function in the target package that populates the field in From
+	//
+	// Signature: func(src *api.Account) T
+	From string
+
+	// To names a function in the target package that receives the field in To, the field is ignored otherwise
+	//
+	// Signature: func(value T, dst *api.Account)
+	To string
+}
+
+// AddField adds a field that only exists on the target, eg: a local cache flag or a derived value
+//
+// typ is written as it would be in the target package, eg: "string" or "time.Time"
+func (b *MappingBuilder) AddField(name, typ string, opts ...FieldOptions) *MappingBuilder {
+	var opt FieldOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	b.config.ExtraFields = append(b.config.ExtraFields, types.ExtraField{
+		Name:     name,
+		Type:     typ,
+		Import:   opt.Import,
+		FromFunc: opt.From,
+		ToFunc:   opt.To,
+	})
+	return b
+}
+
+// MatchWith sets the strategies used to match fields in MapBetween, tried in order
+//
+// Fields matched by a strategy aren't considered by the ones after it, a field matching more than one
+// field within a single strategy fails the build as ambiguous
+func (b *MappingBuilder) MatchWith(strategies ...MatchStrategy) *MappingBuilder {
+	b.strategies = append(b.strategies, strategies...)
+	return b
+}
+
+// Ignore leaves fields of an existing target zero in From, see MapBetween
+func (b *MappingBuilder) Ignore(targetFields ...string) *MappingBuilder {
+	for _, field := range targetFields {
+		b.config.IgnoreFields[field] = true
+	}
+	return b
+}
+
 // WithTargetName allows a custom target struct name
 //
 // Derives name from source if not set
@@ -85,65 +272,539 @@ func (b *MappingBuilder) WithTargetName(name string) *MappingBuilder {
 	return b
 }
 
+// WithMirrorComments adds a reference to the mirrored source to generated doc comments,
+// eg: "Mirrors api.Account.ID"
+//
+// Source doc and line comments are copied either way
+func (b *MappingBuilder) WithMirrorComments() *MappingBuilder {
+	b.config.MirrorComments = true
+	return b
+}
+
+// OmitPolicy decides what To does with source fields the target doesn't carry, see WithOmitPolicy
+type OmitPolicy = types.OmitPolicy
+
+const (
+	OmitZero     = types.OmitZero     // To leaves them zero (default)
+	OmitFromBase = types.OmitFromBase // ToWith(base) copies them from base, replaces To
+	OmitNoTo     = types.OmitNoTo     // To isn't generated
+)
+
+// WithOmitPolicy sets what To does with omitted source fields (and fields of partially pulled up structs)
+//
+// By default they're left zero, so sending the result of To back to its source wipes them.
+// Mappings converting nested values with a mapping that doesn't generate To fail to generate their own
+func (b *MappingBuilder) WithOmitPolicy(policy OmitPolicy) *MappingBuilder {
+	b.config.OmitPolicy = policy
+	return b
+}
+
+// ApplyOptions configures ApplyTo, see WithApplyTo
+type ApplyOptions struct {
+	// SkipZero leaves source fields alone when their target field is zero, eg: for PATCH requests
+	SkipZero bool
+}
+
+// WithApplyTo generates ApplyTo (eg: func (t *Account) ApplyTo(dst *api.Account)), which writes the mapped
+// fields onto an existing source value
+//
+// Fields the target doesn't carry are left intact, registered nested types with ApplyTo are applied recursively
+func (b *MappingBuilder) WithApplyTo(opts ...ApplyOptions) *MappingBuilder {
+	var opt ApplyOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	b.config.ApplyTo = true
+	b.config.ApplySkipZero = opt.SkipZero
+	return b
+}
+
+// WithClone generates Clone() *Account on the target, which returns a deep copy
+//
+// Registered nested types are copied with their own Clone if they have one, other structs field by field.
+// Interfaces and unexported fields of other packages (eg: time.Time's) are copied as is
+func (b *MappingBuilder) WithClone() *MappingBuilder {
+	b.config.Clone = true
+	return b
+}
+
+// WithCompare generates Equal(other *Account) bool and Diff(other *Account) []FieldChange on the target
+//
+// Registered nested types with Equal and Diff are compared field by field, target-only fields are ignored.
+// FieldChange is generated once into the target package
+func (b *MappingBuilder) WithCompare() *MappingBuilder {
+	b.config.Compare = true
+	return b
+}
+
+// WithStyle sets how converters are generated for this mapping, see ModelGen.WithStyle
+func (b *MappingBuilder) WithStyle(style Style) *MappingBuilder {
+	b.style = style
+	return b
+}
+
+// FuncNames overrides the names of package-level converters, see WithFuncNames
+//
+// Empty names keep their default
+type FuncNames struct {
+	From     string // default: AccountFromAPI(src *api.Account) *Account
+	To       string // default: AccountToAPI(t *Account) api.Account
+	Apply    string // default: ApplyAccountToAPI(t *Account, dst *api.Account), see WithApplyTo
+	Validate string // default: ValidateAccount(t *Account) error, see Validate
+}
+
+// WithFuncNames renames the package-level converters generated with StyleFuncs (or for existing targets)
+func (b *MappingBuilder) WithFuncNames(names FuncNames) *MappingBuilder {
+	b.funcNames = names
+	return b
+}
+
+// SliceFuncNames overrides the names of the bulk converters generated for a mapping, see WithSliceFuncs
+//
+// Empty names keep their default, "-" skips generating the converter
+type SliceFuncNames struct {
+	From    string // default: AccountsFromAPI(src []api.Account) []Account
+	To      string // default: AccountsToAPI(t []Account) []api.Account
+	PtrFrom string // default: AccountPtrsFromAPI(src []*api.Account) []*Account
+	PtrTo   string // default: AccountPtrsToAPI(t []*Account) []*api.Account
+}
+
+// WithSliceFuncs renames (or skips) the bulk converters generated for every mapping
+func (b *MappingBuilder) WithSliceFuncs(names SliceFuncNames) *MappingBuilder {
+	b.sliceFuncs = names
+	return b
+}
+
+// Generic generates a generic target with generic converters from a generic source, whatever type arguments
+// the source was registered with, eg: Register(&api.Page[any]{}).Generic() generates
+// type Page[T any] struct and func (t *Page[T]) From(src *api.Page[T]) *Page[T]
+//
+// Fields of a type parameter's type are copied as is. Without Generic, instantiated sources get a concrete
+// target with the type arguments substituted, named after them by default (eg: PageUser for api.Page[api.User])
+func (b *MappingBuilder) Generic() *MappingBuilder {
+	b.generic = true
+	return b
+}
+
 // Build builds struct with mapping methods
 func (b *MappingBuilder) Build() error {
 	// read info from source struct
-	sourceInfo, err := b.parent.reader.Read(b.source)
+	read := b.parent.reader.Read
+	if b.generic {
+		read = b.parent.reader.ReadGeneric
+	}
+	sourceInfo, err := read(b.source)
 	if err != nil {
 		return err
 	}
 
+	if b.generic && (b.target != nil || len(b.config.Groups) > 0) {
+		return fmt.Errorf("%s: Generic can't be combined with MapBetween or Group", sourceInfo.TypeName)
+	}
+
+	if b.target != nil {
+		return b.buildBetween(sourceInfo)
+	}
+
 	// derive target name if not set
 	targetTypeName := b.targetName
 	if targetTypeName == "" {
-		targetTypeName = sourceInfo.TypeName
+		targetTypeName = sourceInfo.TypeName + instanceSuffix(sourceInfo.TypeArgs)
 	}
 
 	// generate target struct info from source
-	targetInfo := b.deriveTargetInfo(sourceInfo, targetTypeName)
+	targetInfo, err := b.deriveTargetInfo(sourceInfo, targetTypeName)
+	if err != nil {
+		return err
+	}
+
+	// target field names must be unique
+	fieldNames := make(map[string]bool)
+	for _, field := range targetInfo.Fields {
+		if fieldNames[field.Name] {
+			return fmt.Errorf("%s: duplicate target field %s", targetTypeName, field.Name)
+		}
+		fieldNames[field.Name] = true
+	}
+	if err := b.resolveAccessors(targetInfo); err != nil {
+		return err
+	}
 
 	b.config.SourceType = sourceInfo
 	b.config.TargetType = targetInfo
+	targetInfo.TypeParams = sourceInfo.TypeParams
+	if err := b.resolveEnumMaps(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveTimes(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveDerefs(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveOptionals(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveConversions(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveRules(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if b.style == StyleFuncs {
+		b.setFuncNames(sourceInfo, targetInfo)
+	}
+	b.config.SliceFuncs = b.sliceFuncNames(sourceInfo, targetInfo)
 
 	b.parent.configs = append(b.parent.configs, b.config)
 	return nil
 }
 
-// deriveTargetInfo creates a target StructInfo from the source, applying omit and field mappings
-func (b *MappingBuilder) deriveTargetInfo(sourceInfo *types.StructInfo, targetTypeName string) *types.StructInfo {
+// buildBetween builds converters between the source and an existing target struct
+func (b *MappingBuilder) buildBetween(sourceInfo *types.StructInfo) error {
+	if b.targetName != "" || len(b.config.Flatten) > 0 || len(b.config.Groups) > 0 || len(b.config.ExtraFields) > 0 ||
+		b.config.Compare || b.config.Clone {
+		return fmt.Errorf("%s: WithTargetName, Flatten, Group, AddField, WithCompare and WithClone need a generated target",
+			sourceInfo.TypeName)
+	}
+
+	targetInfo, err := b.parent.reader.Read(b.target)
+	if err != nil {
+		return err
+	}
+	mappingName := sourceInfo.PackageName + "." + sourceInfo.TypeName + " -> " +
+		targetInfo.PackageName + "." + targetInfo.TypeName
+	if err := b.omitUnexported(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+
+	// source path of each target field, custom mappings first
+	sources := make(map[string]string)
+	for sourcePath, targetField := range b.config.FieldMap {
+		if !hasField(targetInfo, targetField) {
+			return fmt.Errorf("%s: can't map %s to %s, no such target field", mappingName, sourcePath, targetField)
+		}
+		if err := b.readNestedTypes(sourceInfo, sourcePath); err != nil {
+			return fmt.Errorf("%s: can't map %s: %w", mappingName, sourcePath, err)
+		}
+		sources[targetField] = sourcePath
+	}
+
+	// match the remaining fields, leaving out anything already accounted for
+	unmappedSource := &types.StructInfo{TypeName: sourceInfo.TypeName}
+	for _, field := range sourceInfo.Fields {
+		if _, mapped := b.config.FieldMap[field.Name]; !mapped && !b.config.OmitFields[field.Name] {
+			unmappedSource.Fields = append(unmappedSource.Fields, field)
+		}
+	}
+	unmappedTarget := &types.StructInfo{TypeName: targetInfo.TypeName}
+	for _, field := range targetInfo.Fields {
+		if _, mapped := sources[field.Name]; !mapped && !b.config.IgnoreFields[field.Name] {
+			unmappedTarget.Fields = append(unmappedTarget.Fields, field)
+		}
+	}
+
+	matcher := &mapper.FieldMatcher{Strategies: b.strategies}
+	matches, err := matcher.MatchFields(unmappedSource, unmappedTarget)
+	if err != nil {
+		return fmt.Errorf("%s: %w", mappingName, err)
+	}
+	for sourceField, targetField := range matches {
+		sources[targetField] = sourceField
+	}
+
+	// every field must be accounted for
+	var unmatchedSource, unmatchedTarget []string
+	used := make(map[string]bool)
+	for i, targetField := range targetInfo.Fields {
+		sourcePath, ok := sources[targetField.Name]
+		if !ok {
+			if !b.config.IgnoreFields[targetField.Name] {
+				unmatchedTarget = append(unmatchedTarget, targetField.Name)
+			}
+			continue
+		}
+
+		targetInfo.Fields[i].Source = sourcePath
+		used[strings.Split(sourcePath, ".")[0]] = true
+	}
+	for _, sourceField := range sourceInfo.Fields {
+		if !used[sourceField.Name] && !b.config.OmitFields[sourceField.Name] {
+			unmatchedSource = append(unmatchedSource, sourceField.Name)
+		}
+	}
+
+	if len(unmatchedSource) > 0 || len(unmatchedTarget) > 0 {
+		return fmt.Errorf("%s: unmatched fields\n\tsource: %s (see Omit)\n\ttarget: %s (see Ignore)",
+			mappingName, strings.Join(unmatchedSource, ", "), strings.Join(unmatchedTarget, ", "))
+	}
+
+	b.config.SourceType = sourceInfo
+	b.config.TargetType = targetInfo
+	b.config.ExistingTarget = true
+	if err := b.resolveEnumMaps(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveTimes(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveDerefs(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveOptionals(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveConversions(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	if err := b.resolveRules(sourceInfo, targetInfo); err != nil {
+		return err
+	}
+	b.setFuncNames(sourceInfo, targetInfo)
+	b.config.SliceFuncs = b.sliceFuncNames(sourceInfo, targetInfo)
+
+	b.parent.configs = append(b.parent.configs, b.config)
+	return nil
+}
+
+// setFuncNames names the package-level converters, applying overrides to the defaults
+func (b *MappingBuilder) setFuncNames(sourceInfo, targetInfo *types.StructInfo) {
+	pkgName := exportedName(sourceInfo.PackageName)
+	name := func(override, def string) string {
+		if override != "" {
+			return override
+		}
+		return def
+	}
+
+	b.config.FromFuncName = name(b.funcNames.From, targetInfo.TypeName+"From"+pkgName)
+	b.config.ToFuncName = name(b.funcNames.To, targetInfo.TypeName+"To"+pkgName)
+	if b.config.ApplyTo {
+		b.config.ApplyFuncName = name(b.funcNames.Apply, "Apply"+targetInfo.TypeName+"To"+pkgName)
+	}
+	if b.config.Validate {
+		b.config.ValidateFuncName = name(b.funcNames.Validate, "Validate"+targetInfo.TypeName)
+	}
+}
+
+// sliceFuncNames returns the names of the bulk converters, applying overrides to the defaults
+func (b *MappingBuilder) sliceFuncNames(sourceInfo, targetInfo *types.StructInfo) types.SliceFuncs {
+	pkgName := exportedName(sourceInfo.PackageName)
+	name := func(override, def string) string {
+		switch override {
+		case "":
+			return def
+		case "-":
+			return ""
+		}
+		return override
+	}
+
+	return types.SliceFuncs{
+		From:    name(b.sliceFuncs.From, plural(targetInfo.TypeName)+"From"+pkgName),
+		To:      name(b.sliceFuncs.To, plural(targetInfo.TypeName)+"To"+pkgName),
+		PtrFrom: name(b.sliceFuncs.PtrFrom, targetInfo.TypeName+"PtrsFrom"+pkgName),
+		PtrTo:   name(b.sliceFuncs.PtrTo, targetInfo.TypeName+"PtrsTo"+pkgName),
+	}
+}
+
+// readNestedTypes reads the structs on the way to a nested source field path, eg: "Settings" for "Settings.Theme"
+func (b *MappingBuilder) readNestedTypes(sourceInfo *types.StructInfo, sourcePath string) error {
+	parts := strings.Split(sourcePath, ".")
+	structInfo := sourceInfo
+	for i := 0; i < len(parts)-1; i++ {
+		path := strings.Join(parts[:i+1], ".")
+		nestedInfo, ok := b.config.NestedTypes[path]
+		if !ok {
+			field, found := findField(structInfo, parts[i])
+			if !found {
+				return fmt.Errorf("no such field %s", path)
+			}
+
+			var err error
+			nestedInfo, err = b.parent.reader.ReadFieldType(structInfo, field)
+			if err != nil {
+				return err
+			}
+			b.config.NestedTypes[path] = nestedInfo
+		}
+		structInfo = nestedInfo
+	}
+
+	if _, found := findField(structInfo, parts[len(parts)-1]); !found {
+		return fmt.Errorf("no such field %s", sourcePath)
+	}
+	return nil
+}
+
+// deriveTargetInfo creates a target StructInfo from the source, applying omit, flatten and field mappings
+func (b *MappingBuilder) deriveTargetInfo(sourceInfo *types.StructInfo, targetTypeName string) (*types.StructInfo, error) {
 	targetInfo := &types.StructInfo{
 		PackageName: b.parent.targetPackage,
+		PackagePath: b.parent.targetPath,
 		TypeName:    targetTypeName,
+		Doc:         sourceInfo.Doc,
 		Fields:      []types.FieldInfo{},
 	}
 
 	// build target fields from source
-	for _, sourceField := range sourceInfo.Fields {
-		if b.config.OmitFields[sourceField.Name] {
+	if err := b.addTargetFields(targetInfo, sourceInfo, "", "", true); err != nil {
+		return nil, err
+	}
+
+	// nested paths must exist, a typo would otherwise silently map nothing
+	for path := range b.config.Flatten {
+		if _, ok := b.config.NestedTypes[path]; !ok {
+			return nil, fmt.Errorf("%s: can't flatten %s, no such field", targetTypeName, path)
+		}
+	}
+	for path := range b.config.FieldMap {
+		if strings.Contains(path, ".") && !hasTargetField(targetInfo, path) && b.findGroup(path) == nil {
+			return nil, fmt.Errorf("%s: can't map %s, no such field", targetTypeName, path)
+		}
+	}
+	for _, group := range b.config.Groups {
+		if len(group.Type.Fields) != len(group.SourceFields) {
+			return nil, fmt.Errorf("%s: can't group %s, fields %s not found", targetTypeName, group.Name,
+				strings.Join(group.SourceFields, ", "))
+		}
+	}
+
+	for _, extraField := range b.config.ExtraFields {
+		targetInfo.Fields = append(targetInfo.Fields, types.FieldInfo{
+			Name: extraField.Name,
+			Type: extraField.Type,
+		})
+	}
+
+	return targetInfo, nil
+}
+
+// addTargetFields adds a target field for each field of structInfo (found at path in the source),
+// replacing flattened structs with their own fields
+//
+// all includes fields without a custom mapping, which is false for structs that only have some fields pulled up
+func (b *MappingBuilder) addTargetFields(targetInfo, structInfo *types.StructInfo, path, namePrefix string, all bool) error {
+	for _, sourceField := range structInfo.Fields {
+		fieldPath := joinPath(path, sourceField.Name)
+		if b.config.OmitFields[fieldPath] {
 			continue
 		}
 
-		// get target field name & check for custom mapping
-		targetFieldName := sourceField.Name
-		if mappedName, ok := b.config.FieldMap[sourceField.Name]; ok {
-			targetFieldName = mappedName
+		// unexported fields can only be read from the package declaring them
+		if !token.IsExported(sourceField.Name) && !b.parent.readable(structInfo) {
+			if err := b.skipUnexported(targetInfo.TypeName, fieldPath, structInfo); err != nil {
+				return err
+			}
+			b.config.OmitFields[fieldPath] = true
+			continue
+		}
+
+		// flattened structs are replaced by their fields
+		flattenPrefix, flatten := b.config.Flatten[fieldPath]
+		if flatten || b.hasNestedMapping(fieldPath) {
+			nestedInfo, err := b.parent.reader.ReadFieldType(structInfo, sourceField)
+			if err != nil {
+				return fmt.Errorf("%s: can't flatten %s: %w", targetInfo.TypeName, fieldPath, err)
+			}
+			b.config.NestedTypes[fieldPath] = nestedInfo
+
+			if err := b.addTargetFields(targetInfo, nestedInfo, fieldPath, namePrefix+flattenPrefix, flatten); err != nil {
+				return err
+			}
+			continue
 		}
 
-		// remove source package prefix for nested
-		cleanedType := b.cleanTypeName(sourceField.Type, sourceInfo.PackageName)
+		// grouped fields are added to their group, which takes the place of the first one
+		if group := b.findGroup(fieldPath); group != nil {
+			if len(group.Type.Fields) == 0 {
+				targetInfo.Fields = append(targetInfo.Fields, types.FieldInfo{
+					Name:     group.Name,
+					Type:     group.Type.TypeName,
+					IsNested: true,
+				})
+			}
+
+			groupFieldName, ok := b.config.FieldMap[fieldPath]
+			if !ok {
+				groupFieldName = strings.TrimPrefix(sourceField.Name, group.Name)
+				if groupFieldName == "" || !unicode.IsUpper(rune(groupFieldName[0])) {
+					groupFieldName = sourceField.Name
+				}
+			}
+
+			group.Type.Fields = append(group.Type.Fields, types.FieldInfo{
+				Name:      groupFieldName,
+				Type:      sourceField.Type,
+				IsPointer: sourceField.IsPointer,
+				IsSlice:   sourceField.IsSlice,
+				IsNested:  sourceField.IsNested,
+				Source:    fieldPath,
+				Doc:       sourceField.Doc,
+				Comment:   sourceField.Comment,
+			})
+			continue
+		}
+
+		// get target field name & check for custom mapping
+		targetFieldName, ok := b.config.FieldMap[fieldPath]
+		if !ok {
+			if !all {
+				continue
+			}
+			targetFieldName = namePrefix + sourceField.Name
+		}
 
 		targetField := types.FieldInfo{
 			Name:      targetFieldName,
-			Type:      cleanedType,
+			Type:      sourceField.Type,
 			IsPointer: sourceField.IsPointer,
 			IsSlice:   sourceField.IsSlice,
 			IsNested:  sourceField.IsNested,
+			Source:    fieldPath,
+			Doc:       sourceField.Doc,
+			Comment:   sourceField.Comment,
 		}
 
 		targetInfo.Fields = append(targetInfo.Fields, targetField)
 	}
 
-	return targetInfo
+	return nil
+}
+
+// hasNestedMapping reports whether any field below path is mapped or flattened
+func (b *MappingBuilder) hasNestedMapping(path string) bool {
+	for nestedPath := range b.config.FieldMap {
+		if strings.HasPrefix(nestedPath, path+".") {
+			return true
+		}
+	}
+	for nestedPath := range b.config.Flatten {
+		if strings.HasPrefix(nestedPath, path+".") {
+			return true
+		}
+	}
+	for _, group := range b.config.Groups {
+		for _, nestedPath := range group.SourceFields {
+			if strings.HasPrefix(nestedPath, path+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findGroup finds the group a source field path belongs to
+func (b *MappingBuilder) findGroup(path string) *types.FieldGroup {
+	for i, group := range b.config.Groups {
+		for _, groupedPath := range group.SourceFields {
+			if groupedPath == path {
+				return &b.config.Groups[i]
+			}
+		}
+	}
+	return nil
 }
 
 func (m *ModelGen) Generate(outputDir string) error {
@@ -151,52 +812,329 @@ func (m *ModelGen) Generate(outputDir string) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
+	if err := m.resolveTargets(outputDir); err != nil {
+		return err
+	}
+
+	m.readStructs()
+	m.generator.SetMappings(m.configs)
+
+	compare, validate := false, false
 	for _, config := range m.configs {
 		if err := m.generateFile(outputDir, config); err != nil {
 			return err
 		}
+		compare = compare || config.Compare
+		validate = validate || config.Validate
+	}
+
+	// types shared by the generated methods, removed once no mapping uses them
+	if compare {
+		code := m.generator.GenerateFieldChange()
+		if err := m.writeFile(outputDir, toSnakeCase(generator.FieldChangeType)+".go", code); err != nil {
+			return err
+		}
+	} else if err := removeGenerated(outputDir, toSnakeCase(generator.FieldChangeType)+".go"); err != nil {
+		return err
+	}
+	if validate {
+		code := m.generator.GenerateValidationError()
+		if err := m.writeFile(outputDir, toSnakeCase(generator.ValidationErrorType)+".go", code); err != nil {
+			return err
+		}
+	} else if err := removeGenerated(outputDir, toSnakeCase(generator.ValidationErrorType)+".go"); err != nil {
+		return err
+	}
+
+	// tests of the MapEnum tables
+	if code := m.generator.GenerateEnumTests(m.configs); code != "" {
+		if err := m.writeFile(outputDir, "enum_maps_test.go", code); err != nil {
+			return err
+		}
+	} else if err := removeGenerated(outputDir, "enum_maps_test.go"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// removeGenerated removes a file a previous run generated into outputDir, leaving hand-written files alone
+func removeGenerated(outputDir, filename string) error {
+	path := filepath.Join(outputDir, filename)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	if !bytes.HasPrefix(data, []byte(generatedHeader)) {
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", filename, err)
+	}
+	return nil
+}
+
+// resolveTargets sets the target import path and ModelGen options on every mapping (inferring the path from outputDir if needed)
+// and checks the generated targets can share a package
+func (m *ModelGen) resolveTargets(outputDir string) error {
+	targetPath := m.targetPath
+	if targetPath == "" {
+		// not fatal, generated code only needs the path to detect same package mappings
+		targetPath, _ = m.reader.DirToPkgPath(outputDir)
+	}
+
+	targetNames := make(map[string]string)
+	for _, config := range m.configs {
+		if config.Compare {
+			targetNames[generator.FieldChangeType] = "WithCompare"
+		}
+		if config.Validate {
+			targetNames[generator.ValidationErrorType] = "Validate"
+			targetNames[generator.PrefixValidationErrors] = "Validate"
+		}
+	}
+	for i := range m.configs {
+		config := &m.configs[i]
+		config.OutputPath = targetPath
+		config.DeepCopy = m.deepCopy
+
+		// Build guessed the target package by name without WithTargetPath
+		if field := unreadableField(*config, targetPath); field != "" && targetPath != "" {
+			return fmt.Errorf("%s is unexported and can't be read from %s, see WithTargetPath and WithUnexported", field, targetPath)
+		}
+
+		// existing targets only add converters to the package
+		var declared []string
+		if config.FromFuncName != "" {
+			declared = append(declared, config.FromFuncName, config.ToFuncName)
+		}
+		if config.ApplyFuncName != "" {
+			declared = append(declared, config.ApplyFuncName)
+		}
+		if config.ValidateFuncName != "" {
+			declared = append(declared, config.ValidateFuncName)
+		}
+		if config.Checked && config.FromFuncName != "" {
+			declared = append(declared, config.FromFuncName+"E", config.ToFuncName+"E")
+		}
+		for _, enumMap := range config.EnumMaps {
+			declared = append(declared, enumMap.FromFunc, enumMap.ToFunc)
+		}
+		for _, conversion := range config.Conversions {
+			declared = append(declared, conversion.FromFunc, conversion.ToFunc)
+		}
+		for _, rules := range config.Rules {
+			for _, rule := range rules {
+				if rule.Var != "" {
+					declared = append(declared, rule.Var)
+				}
+			}
+		}
+		declared = append(declared, config.SliceFuncs.Names()...)
+		if !config.ExistingTarget {
+			config.TargetType.PackagePath = targetPath
+			declared = append(declared, config.TargetType.TypeName)
+		}
+		for _, group := range config.Groups {
+			group.Type.PackagePath = targetPath
+			declared = append(declared, group.Type.TypeName)
+		}
+		if config.Enum != nil {
+			for _, value := range config.Enum.Values {
+				declared = append(declared, value.TargetName)
+			}
+		}
+
+		// every target is declared in the same package
+		sourceName := config.SourceType.PackagePath + "." + config.SourceType.TypeName
+		if len(config.SourceType.TypeArgs) > 0 {
+			sourceName += "[" + strings.Join(config.SourceType.TypeArgs, ", ") + "]"
+		}
+		for _, name := range declared {
+			if other, ok := targetNames[name]; ok {
+				return fmt.Errorf("%s and %s both generate %s, see WithTargetName",
+					other, sourceName, name)
+			}
+			targetNames[name] = sourceName
+		}
+
+		if config.SourceType.PackagePath != targetPath || config.ExistingTarget {
+			continue
+		}
+		if config.SourceType.PackageName != config.TargetType.PackageName {
+			return fmt.Errorf("%s is generated into its source package %s but the target package is named %s",
+				config.TargetType.TypeName, config.SourceType.PackageName, config.TargetType.PackageName)
+		}
+		if config.SourceType.TypeName == config.TargetType.TypeName {
+			return fmt.Errorf("%s is generated into its source package and needs a different name, see WithTargetName",
+				config.TargetType.TypeName)
+		}
 	}
 
 	return nil
 }
 
+// readStructs reads the unregistered structs written in the source field types of every mapping, and in their own
+// field types, so Clone and WithDeepCopy can copy them field by field
+func (m *ModelGen) readStructs() {
+	needed := m.deepCopy
+	for _, config := range m.configs {
+		needed = needed || config.Clone
+	}
+	if !needed {
+		return
+	}
+
+	read := make(map[string]*types.StructInfo) // by package path and type name, nil for types that aren't structs
+	for _, config := range m.configs {
+		if config.Enum != nil {
+			continue
+		}
+		m.readFieldStructs(config.SourceType, read)
+		for _, nestedInfo := range config.NestedTypes {
+			m.readFieldStructs(nestedInfo, read)
+		}
+	}
+}
+
+// readFieldStructs fills info.Structs with the structs written in its field types, through pointers, slices and maps
+func (m *ModelGen) readFieldStructs(info *types.StructInfo, read map[string]*types.StructInfo) {
+	if info.Structs != nil {
+		return
+	}
+	info.Structs = make(map[string]*types.StructInfo)
+
+	for _, field := range info.Fields {
+		expr, err := parser.ParseExpr(field.Type)
+		if err != nil {
+			continue
+		}
+		expr = elemType(expr)
+
+		var pkgPath, typeName string
+		switch t := expr.(type) {
+		case *ast.Ident:
+			if gotypes.Universe.Lookup(t.Name) == nil {
+				pkgPath, typeName = info.PackagePath, t.Name
+			}
+		case *ast.SelectorExpr:
+			if x, ok := t.X.(*ast.Ident); ok {
+				pkgPath, typeName = info.Imports[x.Name], t.Sel.Name
+			}
+		}
+		if typeName == "" || pkgPath == "" || hasTypeParam(info, typeName) || m.registered(pkgPath, typeName) {
+			continue
+		}
+
+		key := pkgPath + "." + typeName
+		structInfo, ok := read[key]
+		if !ok {
+			// not fatal, types that aren't structs (or can't be read) are copied as is
+			structInfo, _ = m.reader.ReadNamed(pkgPath, typeName)
+			if structInfo != nil && len(structInfo.TypeParams) > 0 {
+				structInfo = nil
+			}
+			read[key] = structInfo
+			if structInfo != nil {
+				m.readFieldStructs(structInfo, read)
+			}
+		}
+		if structInfo != nil {
+			info.Structs[gotypes.ExprString(expr)] = structInfo
+		}
+	}
+}
+
+// elemType returns the type a pointer, slice, array or map type holds values of, recursively
+func elemType(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return elemType(t.X)
+	case *ast.ArrayType:
+		return elemType(t.Elt)
+	case *ast.MapType:
+		return elemType(t.Value)
+	}
+	return expr
+}
+
+// hasTypeParam reports whether name is a type parameter of a generic struct
+func hasTypeParam(info *types.StructInfo, name string) bool {
+	for _, param := range info.TypeParams {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// registered reports whether a struct is the source of a registered mapping
+func (m *ModelGen) registered(pkgPath, typeName string) bool {
+	for _, config := range m.configs {
+		if config.SourceType.PackagePath == pkgPath && config.SourceType.TypeName == typeName {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *ModelGen) generateFile(outputDir string, config types.MappingConfig) error {
+	// generate struct and methods
+	code, err := m.generator.GenerateStructAndMethods(config)
+	if err != nil {
+		return err
+	}
+
+	// create filename
+	filename := toSnakeCase(config.TargetType.TypeName) + ".go"
+	if config.ExistingTarget {
+		filename = toSnakeCase(config.TargetType.TypeName) + "_mapping.go"
+	}
+
+	return m.writeFile(outputDir, filename, code)
+}
+
+// generatedHeader starts every generated file
+const generatedHeader = "// Code generated by modelgen. DO NOT EDIT.\n"
+
+// writeFile writes generated code into the target package, along with the imports it uses
+func (m *ModelGen) writeFile(outputDir, filename, code string) error {
 	var buf bytes.Buffer
 
-	buf.WriteString("// Code generated by modelgen. DO NOT EDIT.\n")
+	buf.WriteString(generatedHeader)
 	fmt.Fprintf(&buf, "// version: %s\n", util.GetVersion())
 	fmt.Fprintf(&buf, "// generated at: %s\n", time.Now().UTC().Format(time.RFC3339))
 	buf.WriteString("\n")
 
 	// write package declaration
-	fmt.Fprintf(&buf, "package %s\n\n", config.TargetType.PackageName)
+	fmt.Fprintf(&buf, "package %s\n\n", m.targetPackage)
 
-	// collect imports for this struct
-	imports := m.collectImportsForConfig(config)
+	// write imports used by the generated code
+	imports := m.generator.Imports()
 	if len(imports) > 0 {
 		buf.WriteString("import (\n")
-		for _, imp := range imports {
-			fmt.Fprintf(&buf, "\t\"%s\"\n", imp)
+		for i, imp := range imports {
+			// separate standard library imports
+			if i > 0 && imports[i-1].IsStd() != imp.IsStd() {
+				buf.WriteString("\n")
+			}
+			fmt.Fprintf(&buf, "\t%s \"%s\"\n", imp.Name, imp.Path)
 		}
 		buf.WriteString(")\n\n")
 	}
 
-	// generate struct and methods
-	code, err := m.generator.GenerateStructAndMethods(config)
-	if err != nil {
-		return err
-	}
 	buf.WriteString(code)
 
 	// format
 	formatted, err := format.Source(buf.Bytes())
 	if err != nil {
 		return fmt.Errorf("format error for %s: %w\nGenerated code:\n%s",
-			config.TargetType.TypeName, err, buf.String())
+			filename, err, buf.String())
 	}
 
-	// create filename
-	filename := toSnakeCase(config.TargetType.TypeName) + ".go"
 	filepath := filepath.Join(outputDir, filename)
 
 	// write file
@@ -209,89 +1147,104 @@ func (m *ModelGen) generateFile(outputDir string, config types.MappingConfig) er
 
 // --- Helpers ---
 
-func (m *ModelGen) collectImportsForConfig(config types.MappingConfig) []string {
-	importSet := make(map[string]bool)
-
-	// Add source package import
-	if config.SourceType.PackagePath != "" &&
-		config.SourceType.PackagePath != config.TargetType.PackagePath {
-		importSet[config.SourceType.PackagePath] = true
+func toSnakeCase(s string) string {
+	var result []rune
+	for i, r := range s {
+		if i > 0 && unicode.IsUpper(r) {
+			result = append(result, '_')
+		}
+		result = append(result, unicode.ToLower(r))
 	}
+	return string(result)
+}
 
-	// Scan fields for additional imports
-	for _, field := range config.SourceType.Fields {
-		if imp := m.extractImportFromType(field.Type); imp != "" {
-			importSet[imp] = true
+func findField(structInfo *types.StructInfo, name string) (types.FieldInfo, bool) {
+	for _, field := range structInfo.Fields {
+		if field.Name == name {
+			return field, true
 		}
 	}
+	return types.FieldInfo{}, false
+}
 
-	// Convert to sorted slice for deterministic output
-	imports := make([]string, 0, len(importSet))
-	for imp := range importSet {
-		imports = append(imports, imp)
+// sourceField finds a source field by its path, along with the struct declaring it
+func (b *MappingBuilder) sourceField(sourceInfo *types.StructInfo, path string) (*types.StructInfo, types.FieldInfo, bool) {
+	owner, name := sourceInfo, path
+	if idx := strings.LastIndex(path, "."); idx != -1 {
+		owner, name = b.config.NestedTypes[path[:idx]], path[idx+1:]
 	}
-	sort.Strings(imports)
-
-	return imports
+	if owner == nil {
+		return nil, types.FieldInfo{}, false
+	}
+	field, ok := findField(owner, name)
+	return owner, field, ok
 }
 
-func (m *ModelGen) collectAllImports() []string {
-	importsMap := make(map[string]bool)
+func hasField(structInfo *types.StructInfo, name string) bool {
+	_, ok := findField(structInfo, name)
+	return ok
+}
 
-	for _, config := range m.configs {
-		// add source package import
-		if config.SourceType.PackagePath != "" {
-			importsMap[config.SourceType.PackagePath] = true
-		}
+// exportedName turns a package name into part of an exported identifier, eg: "api" -> "API", "stripe" -> "Stripe"
+func exportedName(pkgName string) string {
+	if len(pkgName) <= 3 {
+		return strings.ToUpper(pkgName)
+	}
+	return strings.ToUpper(pkgName[:1]) + pkgName[1:]
+}
 
-		// check for time.Time
-		// TODO probably need to do this for other types
-		for _, field := range config.SourceType.Fields {
-			if strings.Contains(field.Type, "time.Time") {
-				importsMap["time"] = true
-			}
+// instanceSuffix names the type arguments of an instantiated generic, eg: ["api.User"] -> "User"
+func instanceSuffix(typeArgs []string) string {
+	var suffix strings.Builder
+	for _, arg := range typeArgs {
+		expr, err := parser.ParseExpr(arg)
+		if err != nil {
+			continue
 		}
-		for _, field := range config.TargetType.Fields {
-			if strings.Contains(field.Type, "time.Time") {
-				importsMap["time"] = true
+		ast.Inspect(expr, func(n ast.Node) bool {
+			switch t := n.(type) {
+			case *ast.SelectorExpr:
+				// package qualifiers are left out
+				suffix.WriteString(t.Sel.Name)
+				return false
+			case *ast.Ident:
+				suffix.WriteString(strings.ToUpper(t.Name[:1]) + t.Name[1:])
 			}
-		}
-	}
-
-	imports := make([]string, 0, len(importsMap))
-	for imp := range importsMap {
-		imports = append(imports, imp)
+			return true
+		})
 	}
-	return imports
+	return suffix.String()
 }
 
-func toSnakeCase(s string) string {
-	var result []rune
-	for i, r := range s {
-		if i > 0 && unicode.IsUpper(r) {
-			result = append(result, '_')
-		}
-		result = append(result, unicode.ToLower(r))
+// plural returns the plural of a type name, eg: "Account" -> "Accounts", "Category" -> "Categories"
+//
+// Names that may be plural already get a List suffix instead, eg: "Settings" -> "SettingsList"
+func plural(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "x"),
+		strings.HasSuffix(lower, "z"), strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	case strings.HasSuffix(lower, "s"):
+		return name + "List"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
 	}
-	return string(result)
+	return name + "s"
 }
 
-func (m *ModelGen) extractImportFromType(typeName string) string {
-	clean := strings.TrimLeft(typeName, "*[]")
-
-	// Extract package path (e.g., "github.com/user/pkg.Type" -> "github.com/user/pkg")
-	if idx := strings.LastIndex(clean, "."); idx != -1 {
-		pkgPath := clean[:idx]
-		if strings.Contains(pkgPath, "/") || strings.Contains(pkgPath, ".") {
-			return pkgPath
+func hasTargetField(targetInfo *types.StructInfo, sourcePath string) bool {
+	for _, field := range targetInfo.Fields {
+		if field.Source == sourcePath {
+			return true
 		}
 	}
-
-	return ""
+	return false
 }
 
-func (b *MappingBuilder) cleanTypeName(typeName string, sourcePackage string) string {
-	// "externalservice.Post" -> "Post"
-	sourcePrefix := sourcePackage + "."
-	return strings.ReplaceAll(typeName, sourcePrefix, "")
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
 }
@@ -0,0 +1,183 @@
+package modelgen
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/matt0792/modelgen/pkg/modelgen/testdata/api"
+	"github.com/matt0792/modelgen/pkg/modelgen/testdata/domain"
+)
+
+// testGenerated compiles the code generated into dir and runs test, the source of a _test.go file in its package
+func testGenerated(t *testing.T, dir, test string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "generated_test.go"), []byte(test), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("go", "test", "./"+filepath.ToSlash(dir)).CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated code failed: %v\n%s", err, out)
+	}
+}
+
+// omittedTest checks To zeroes the omitted fields of api.Item
+const omittedTest = `package %s
+
+import (
+	"testing"
+
+	"github.com/matt0792/modelgen/pkg/modelgen/testdata/api"
+)
+
+func TestOmitted(t *testing.T) {
+	if src := %s; src.ID != 1 || src.At != nil || src.Stamps != nil || src.Notes != nil || !src.Created.IsZero() {
+		t.Errorf("To() = %%+v", src)
+	}
+	var _ api.Item
+}
+`
+
+func TestOmittedImportedFields(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles generated code")
+	}
+
+	t.Run("Register", func(t *testing.T) {
+		dir := filepath.Join("testdata", "omitted")
+		t.Cleanup(func() { os.RemoveAll(dir) })
+
+		gen := New("omitted")
+		if err := gen.Register(&api.Item{}).Omit("At", "Stamps", "Notes", "Created").Build(); err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if err := gen.Generate(dir); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		testGenerated(t, dir, fmt.Sprintf(omittedTest, "omitted", "(&Item{ID: 1}).To()"))
+	})
+
+	t.Run("MapBetween", func(t *testing.T) {
+		dir := filepath.Join("testdata", "domain")
+		t.Cleanup(func() {
+			os.Remove(filepath.Join(dir, "item_mapping.go"))
+			os.Remove(filepath.Join(dir, "generated_test.go"))
+		})
+
+		gen := New("domain")
+		if err := gen.MapBetween(&api.Item{}, &domain.Item{}).Omit("At", "Stamps", "Notes", "Created").Build(); err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if err := gen.Generate(dir); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		testGenerated(t, dir, fmt.Sprintf(omittedTest, "domain", "ItemToAPI(&Item{ID: 1})"))
+	})
+}
+
+func TestRemoveGenerated(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // empty for a missing file
+		removed bool
+	}{
+		{name: "generated", content: generatedHeader + "\npackage models\n", removed: true},
+		{name: "hand-written", content: "package models\n", removed: false},
+		{name: "missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "enum_maps_test.go")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := removeGenerated(dir, "enum_maps_test.go"); err != nil {
+				t.Fatalf("removeGenerated() error = %v", err)
+			}
+			_, err := os.Stat(path)
+			if exists := err == nil; tt.content != "" && exists == tt.removed {
+				t.Errorf("removeGenerated() left the file: %v, want %v", exists, !tt.removed)
+			}
+		})
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"Account", "Accounts"},
+		{"Category", "Categories"},
+		{"Day", "Days"},
+		{"Address", "Addresses"},
+		{"Status", "Statuses"},
+		{"Box", "Boxes"},
+		{"Batch", "Batches"},
+		{"Wish", "Wishes"},
+		{"Settings", "SettingsList"},
+		{"Stats", "StatsList"},
+		{"News", "NewsList"},
+		{"Y", "Ys"},
+	}
+
+	for _, tt := range tests {
+		if got := plural(tt.name); got != tt.want {
+			t.Errorf("plural(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// deepCopyTest checks Clone and From copy the unregistered structs of api.Post instead of sharing them
+const deepCopyTest = `package deepcopy
+
+import (
+	"testing"
+
+	"github.com/matt0792/modelgen/pkg/modelgen/testdata/api"
+)
+
+func TestDeepCopy(t *testing.T) {
+	src := &api.Post{
+		Plain:  &api.Plain{B: []int{1}, Next: &api.Plain{B: []int{2}}},
+		Plains: map[string][]api.Plain{"a": {{B: []int{3}}}},
+	}
+	post := (&Post{}).From(src)
+	clone := post.Clone()
+
+	src.Plain.B[0], src.Plain.Next.B[0], src.Plains["a"][0].B[0] = 9, 9, 9
+	if post.Plain.B[0] != 1 || post.Plain.Next.B[0] != 2 || post.Plains["a"][0].B[0] != 3 {
+		t.Errorf("From() shares %+v", post)
+	}
+
+	post.Plain.B[0], post.Plain.Next.B[0], post.Plains["a"][0].B[0] = 9, 9, 9
+	if clone.Plain.B[0] != 1 || clone.Plain.Next.B[0] != 2 || clone.Plains["a"][0].B[0] != 3 {
+		t.Errorf("Clone() shares %+v", clone)
+	}
+}
+`
+
+func TestDeepCopyUnregisteredStructs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles generated code")
+	}
+
+	dir := filepath.Join("testdata", "deepcopy")
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	gen := New("deepcopy").WithDeepCopy()
+	if err := gen.Register(&api.Post{}).WithClone().Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if err := gen.Generate(dir); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	testGenerated(t, dir, deepCopyTest)
+}
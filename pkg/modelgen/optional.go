@@ -0,0 +1,203 @@
+package modelgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"reflect"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// OptionalNames names the funcs and method of the optional type, see WithOptional
+type OptionalNames struct {
+	Some string // package func wrapping a present value, eg: func Some[T any](v T) Optional[T]; "Some" if empty
+	None string // package func returning an absent value, eg: func None[T any]() Optional[T]; the zero value if empty
+	Get  string // method returning the value and whether it's present, eg: func (o Optional[T]) Get() (T, bool); "Get" if empty
+}
+
+// sqlNulls are the nullable types of database/sql, by the field holding their value
+var sqlNulls = map[string]struct{ value, elem string }{
+	"NullString":  {"String", "string"},
+	"NullInt64":   {"Int64", "int64"},
+	"NullInt32":   {"Int32", "int32"},
+	"NullInt16":   {"Int16", "int16"},
+	"NullByte":    {"Byte", "byte"},
+	"NullFloat64": {"Float64", "float64"},
+	"NullBool":    {"Bool", "bool"},
+	"NullTime":    {"Time", "time.Time"},
+}
+
+// WithOptional sets the generic struct type Optional converts nullable fields to, given as any instantiation of it,
+// eg: WithOptional(opt.Optional[int]{})
+func (m *ModelGen) WithOptional(optional interface{}, names ...OptionalNames) *ModelGen {
+	var n OptionalNames
+	if len(names) > 0 {
+		n = names[0]
+	}
+
+	m.optional = optional
+	m.optionalNames = n
+	return m
+}
+
+// Optional converts nullable fields (pointers, sql.NullString and the like, sql.Null[T]) to the optional type
+// set with WithOptional, every nullable field of the source if none are given
+//
+// From wraps present values with Some, nil pointers and invalid sql values are absent.
+// Pointers to registered types are converted as usual
+func (b *MappingBuilder) Optional(fields ...string) *MappingBuilder {
+	b.optionals = append(b.optionals, fields...)
+	b.optionalAll = b.optionalAll || len(fields) == 0
+	return b
+}
+
+// resolveOptionalType resolves the optional type set with WithOptional
+func (m *ModelGen) resolveOptionalType() (*types.OptionalType, error) {
+	t := reflect.TypeOf(m.optional)
+	if t == nil {
+		return nil, fmt.Errorf("no optional type, see WithOptional")
+	}
+
+	// instantiations are named with their type arguments, eg: Optional[int]
+	name, _, generic := strings.Cut(t.Name(), "[")
+	if t.Kind() != reflect.Struct || !generic {
+		return nil, fmt.Errorf("optional type %s is not a generic struct", t)
+	}
+
+	optional := &types.OptionalType{
+		Type: types.TypeRef{
+			PackagePath: t.PkgPath(),
+			PackageName: strings.SplitN(t.String(), ".", 2)[0],
+			Name:        name,
+		},
+		Some: m.optionalNames.Some,
+		None: m.optionalNames.None,
+		Get:  m.optionalNames.Get,
+	}
+	if optional.Some == "" {
+		optional.Some = "Some"
+	}
+	if optional.Get == "" {
+		optional.Get = "Get"
+	}
+
+	if method, ok := t.MethodByName(optional.Get); !ok || method.Type.NumIn() != 1 || method.Type.NumOut() != 2 ||
+		method.Type.Out(1).Kind() != reflect.Bool {
+		return nil, fmt.Errorf("optional type %s has no method %s returning its value and a bool", t, optional.Get)
+	}
+	return optional, nil
+}
+
+// resolveOptionals resolves the fields converted to the optional type
+func (b *MappingBuilder) resolveOptionals(sourceInfo, targetInfo *types.StructInfo) error {
+	if len(b.optionals) == 0 && !b.optionalAll {
+		return nil
+	}
+
+	optionalType, err := b.parent.resolveOptionalType()
+	if err != nil {
+		return fmt.Errorf("%s: %w", targetInfo.TypeName, err)
+	}
+
+	targetFields := b.targetFields(targetInfo)
+
+	paths := b.optionals
+	if b.optionalAll {
+		for _, targetField := range targetFields {
+			path := targetField.Source
+			if path == "" {
+				continue
+			}
+			if _, ok := b.config.EnumMaps[path]; ok {
+				continue
+			}
+			if _, ok := b.config.Derefs[path]; ok {
+				continue
+			}
+			if owner, field, ok := b.sourceField(sourceInfo, path); ok {
+				if _, ok := b.nullable(owner, field); ok {
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+
+	b.config.Optionals = make(map[string]types.Optional)
+	b.config.OptionalType = optionalType
+	for _, path := range paths {
+		var targetField *types.FieldInfo
+		for _, field := range targetFields {
+			if field.Source == path {
+				targetField = field
+			}
+		}
+		if targetField == nil {
+			return fmt.Errorf("%s: can't make %s optional, no such mapped field", targetInfo.TypeName, path)
+		}
+		if _, ok := b.config.EnumMaps[path]; ok {
+			return fmt.Errorf("%s: can't make %s optional, it's converted with MapEnum", targetInfo.TypeName, path)
+		}
+		if _, ok := b.config.Derefs[path]; ok {
+			return fmt.Errorf("%s: can't make %s optional, it's dereferenced", targetInfo.TypeName, path)
+		}
+
+		owner, field, _ := b.sourceField(sourceInfo, path)
+		optional, ok := b.nullable(owner, field)
+		if !ok {
+			return fmt.Errorf("%s: can't make %s optional, %s is not a pointer or sql null type", targetInfo.TypeName, path, field.Type)
+		}
+		b.config.Optionals[path] = optional
+
+		if b.target == nil {
+			targetField.IsPointer = false
+		}
+	}
+	return nil
+}
+
+// nullable describes the value of a nullable field, false if the field isn't a pointer or a database/sql null type
+func (b *MappingBuilder) nullable(owner *types.StructInfo, field types.FieldInfo) (types.Optional, bool) {
+	if elem, ok := strings.CutPrefix(field.Type, "*"); ok {
+		return types.Optional{Elem: elem, Underlying: b.parent.reader.Underlying(owner, elem)}, true
+	}
+
+	expr, err := parser.ParseExpr(field.Type)
+	if err != nil {
+		return types.Optional{}, false
+	}
+
+	// sql.Null[T] holds its value in V
+	if index, ok := expr.(*ast.IndexExpr); ok {
+		if pkg, name, ok := qualifiedName(index.X); ok && name == "Null" && owner.Imports[pkg] == "database/sql" {
+			elem := field.Type[index.Index.Pos()-1 : index.Index.End()-1]
+			return types.Optional{Elem: elem, Underlying: b.parent.reader.Underlying(owner, elem), Value: "V"}, true
+		}
+		return types.Optional{}, false
+	}
+
+	pkg, name, ok := qualifiedName(expr)
+	null, known := sqlNulls[name]
+	if !ok || !known || owner.Imports[pkg] != "database/sql" {
+		return types.Optional{}, false
+	}
+	optional := types.Optional{Elem: null.elem, Value: null.value}
+	if name == "NullTime" {
+		optional.ElemRef = &types.TypeRef{PackagePath: "time", PackageName: "time", Name: "Time"}
+	}
+	return optional, true
+}
+
+// qualifiedName splits a qualified type name, eg: sql.NullString
+func qualifiedName(expr ast.Expr) (pkg, name string, ok bool) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	return ident.Name, sel.Sel.Name, true
+}
@@ -0,0 +1,213 @@
+package modelgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// FieldStatus describes what happens to a field's data in a mapping
+type FieldStatus string
+
+const (
+	FieldDirect     FieldStatus = "direct"      // copied as is to a field with the same name
+	FieldRenamed    FieldStatus = "renamed"     // copied as is to a field with another name or path
+	FieldConverted  FieldStatus = "converted"   // converted with another mapping's From/To, a conversion (eg: Convert) or a type conversion
+	FieldOmitted    FieldStatus = "omitted"     // not carried by the target
+	FieldTargetOnly FieldStatus = "target-only" // only exists on the target
+)
+
+// Report describes the field coverage of every registered mapping, see ModelGen.Report
+type Report struct {
+	Mappings []MappingReport `json:"mappings"`
+}
+
+// MappingReport describes the field coverage of a single mapping
+type MappingReport struct {
+	Source string        `json:"source"` // eg: "api.Account"
+	Target string        `json:"target"` // eg: "models.Account"
+	To     string        `json:"to"`     // generated To method or function, empty if there is none
+	Fields []FieldReport `json:"fields"`
+
+	Warnings []string `json:"warnings,omitempty"` // problems Build worked around, eg: skipped validate tag rules
+}
+
+// FieldReport describes a single source or target-only field
+type FieldReport struct {
+	Source     string      `json:"source,omitempty"` // source field path, empty for target-only fields
+	Target     string      `json:"target,omitempty"` // target field path, empty for omitted fields
+	Status     FieldStatus `json:"status"`
+	ZeroedInTo bool        `json:"zeroedInTo,omitempty"` // the source field is left zero by To
+}
+
+// Report describes what each registered mapping does with every field, so data lost in a
+// round trip (From then To) can be spotted at a glance
+func (m *ModelGen) Report() *Report {
+	m.generator.SetMappings(m.configs)
+
+	report := &Report{Mappings: []MappingReport{}}
+	for _, config := range m.configs {
+		// enums have no fields
+		if config.Enum != nil {
+			continue
+		}
+
+		mapping := MappingReport{
+			Source:   reportType(config.SourceType),
+			Target:   reportType(config.TargetType),
+			To:       reportTo(config),
+			Fields:   []FieldReport{},
+			Warnings: config.Warnings,
+		}
+
+		mapping.Fields = m.reportSourceFields(mapping.Fields, config, config.SourceType, "")
+
+		for _, extraField := range config.ExtraFields {
+			mapping.Fields = append(mapping.Fields, FieldReport{Target: extraField.Name, Status: FieldTargetOnly})
+		}
+		for _, targetField := range config.TargetType.Fields {
+			if config.IgnoreFields[targetField.Name] {
+				mapping.Fields = append(mapping.Fields, FieldReport{Target: targetField.Name, Status: FieldTargetOnly})
+			}
+		}
+
+		report.Mappings = append(report.Mappings, mapping)
+	}
+
+	return report
+}
+
+// reportSourceFields adds a report for each field of structInfo (found at path in the source),
+// descending into flattened structs
+func (m *ModelGen) reportSourceFields(fields []FieldReport, config types.MappingConfig, structInfo *types.StructInfo, path string) []FieldReport {
+	for _, sourceField := range structInfo.Fields {
+		fieldPath := joinPath(path, sourceField.Name)
+		// ToWith can't copy unexported fields of other packages from base either
+		zeroedInTo := config.OmitPolicy == types.OmitZero ||
+			config.OmitPolicy == types.OmitFromBase && !token.IsExported(sourceField.Name) && !m.readable(structInfo)
+		if config.OmitFields[fieldPath] {
+			fields = append(fields, FieldReport{Source: fieldPath, Status: FieldOmitted, ZeroedInTo: zeroedInTo})
+			continue
+		}
+
+		if nestedInfo, ok := config.NestedTypes[fieldPath]; ok {
+			fields = m.reportSourceFields(fields, config, nestedInfo, fieldPath)
+			continue
+		}
+
+		targetField, targetPath, ok := reportTargetField(fieldPath, config)
+		if !ok {
+			// fields left behind by a partially pulled up struct
+			fields = append(fields, FieldReport{Source: fieldPath, Status: FieldOmitted, ZeroedInTo: zeroedInTo})
+			continue
+		}
+
+		status := FieldDirect
+		switch {
+		case m.generator.IsConverted(targetField, config):
+			status = FieldConverted
+		case targetPath != fieldPath:
+			status = FieldRenamed
+		}
+		fields = append(fields, FieldReport{Source: fieldPath, Target: targetPath, Status: status})
+	}
+
+	return fields
+}
+
+// reportTargetField finds the target field a source field path is mapped to, along with its path in the target
+func reportTargetField(sourcePath string, config types.MappingConfig) (types.FieldInfo, string, bool) {
+	for _, field := range config.TargetType.Fields {
+		if field.Source == sourcePath {
+			return field, field.Name, true
+		}
+	}
+	for _, group := range config.Groups {
+		for _, field := range group.Type.Fields {
+			if field.Source == sourcePath {
+				return field, group.Name + "." + field.Name, true
+			}
+		}
+	}
+	return types.FieldInfo{}, "", false
+}
+
+// reportTo returns the name of the To method or function generated for a mapping
+func reportTo(config types.MappingConfig) string {
+	switch {
+	case config.OmitPolicy == types.OmitNoTo:
+		return ""
+	case config.ToFuncName != "":
+		return config.ToFuncName
+	case config.OmitPolicy == types.OmitFromBase:
+		return "ToWith"
+	}
+	return "To"
+}
+
+// WriteText writes the report as a table per mapping
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, mapping := range r.Mappings {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		to := mapping.To
+		if to == "" {
+			to = "no To"
+		}
+		fmt.Fprintf(tw, "%s -> %s (%s)\n", mapping.Source, mapping.Target, to)
+		fmt.Fprintln(tw, "  SOURCE\tTARGET\tSTATUS")
+		for _, field := range mapping.Fields {
+			status := string(field.Status)
+			if field.ZeroedInTo {
+				status += ", zeroed in To"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", dash(field.Source), dash(field.Target), status)
+		}
+		for _, warning := range mapping.Warnings {
+			fmt.Fprintf(tw, "  warning: %s\n", warning)
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+	r.WriteText(&sb)
+	return sb.String()
+}
+
+// reportType names a struct with its package and type arguments (or parameters), eg: "api.Page[User]"
+func reportType(info *types.StructInfo) string {
+	name := info.PackageName + "." + info.TypeName
+
+	var args []string
+	args = append(args, info.TypeArgs...)
+	for _, param := range info.TypeParams {
+		args = append(args, param.Name)
+	}
+	if len(args) > 0 {
+		name += "[" + strings.Join(args, ", ") + "]"
+	}
+	return name
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package modelgen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/matt0792/modelgen/pkg/modelgen/testdata/api"
+)
+
+func TestReportZeroedInTo(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OmitPolicy
+		want   []FieldReport
+	}{
+		{
+			name:   "OmitZero",
+			policy: OmitZero,
+			want: []FieldReport{
+				{Source: "User", Target: "User", Status: FieldDirect},
+				{Source: "Agent", Status: FieldOmitted, ZeroedInTo: true},
+				{Source: "token", Status: FieldOmitted, ZeroedInTo: true},
+			},
+		},
+		{
+			name:   "OmitFromBase copies exported fields from base",
+			policy: OmitFromBase,
+			want: []FieldReport{
+				{Source: "User", Target: "User", Status: FieldDirect},
+				{Source: "Agent", Status: FieldOmitted},
+				{Source: "token", Status: FieldOmitted, ZeroedInTo: true},
+			},
+		},
+		{
+			name:   "OmitNoTo",
+			policy: OmitNoTo,
+			want: []FieldReport{
+				{Source: "User", Target: "User", Status: FieldDirect},
+				{Source: "Agent", Status: FieldOmitted},
+				{Source: "token", Status: FieldOmitted},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := New("models")
+			if err := gen.Register(&api.Session{}).Omit("Agent").WithOmitPolicy(tt.policy).Build(); err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			report := gen.Report()
+			if len(report.Mappings) != 1 {
+				t.Fatalf("Report() has %d mappings, want 1", len(report.Mappings))
+			}
+			if got := report.Mappings[0].Fields; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Report() fields = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package modelgen
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Run runs the generator program's command line, args are usually os.Args[1:]
+//
+//	go run ./gen                 generates into outputDir
+//	go run ./gen generate [dir]  generates into dir, outputDir if not set
+//	go run ./gen report [-json]  prints the mapping report, see Report
+func (m *ModelGen) Run(outputDir string, args []string) error {
+	return m.run(os.Stdout, outputDir, args)
+}
+
+func (m *ModelGen) run(w io.Writer, outputDir string, args []string) error {
+	command := "generate"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "generate":
+		flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() > 0 {
+			outputDir = flags.Arg(0)
+		}
+		return m.Generate(outputDir)
+
+	case "report":
+		flags := flag.NewFlagSet("report", flag.ContinueOnError)
+		asJSON := flags.Bool("json", false, "print the report as JSON")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if *asJSON {
+			return m.Report().WriteJSON(w)
+		}
+		return m.Report().WriteText(w)
+	}
+
+	return fmt.Errorf("unknown command %q, expected generate or report", command)
+}
@@ -0,0 +1,128 @@
+package modelgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matt0792/modelgen/pkg/modelgen/testdata/api"
+)
+
+// newRunGen registers api.Account with an omitted and a renamed field
+func newRunGen(t *testing.T) *ModelGen {
+	t.Helper()
+	gen := New("models")
+	err := gen.Register(&api.Account{}).
+		Omit("Email").
+		MapField("UserName", "Name").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	return gen
+}
+
+func TestRun(t *testing.T) {
+	const text = `api.Account -> models.Account (To)
+  SOURCE     TARGET     STATUS
+  ID         ID         direct
+  UserName   Name       renamed
+  Email      -          omitted, zeroed in To
+  Balance    Balance    direct
+  CreatedAt  CreatedAt  direct
+`
+
+	tests := []struct {
+		name  string
+		args  []string
+		out   string   // printed output
+		files []string // files generated into the output dir, relative to it
+		dir   string   // directory appended to args, relative to the output dir's parent
+		err   string   // empty for none
+	}{
+		{name: "generates by default", files: []string{"account.go"}},
+		{name: "generate", args: []string{"generate"}, files: []string{"account.go"}},
+		{name: "generate into dir", args: []string{"generate"}, dir: "other", files: []string{"account.go"}},
+		{name: "report", args: []string{"report"}, out: text},
+		{name: "unknown flag", args: []string{"report", "-yaml"}, err: "flag provided but not defined: -yaml"},
+		{name: "generate flags", args: []string{"generate", "-json"}, err: "flag provided but not defined: -json"},
+		{name: "unknown command", args: []string{"check"}, err: `unknown command "check", expected generate or report`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			outputDir := filepath.Join(root, "models")
+			args := tt.args
+			if tt.dir != "" {
+				args = append(args, filepath.Join(root, tt.dir))
+			}
+
+			var out bytes.Buffer
+			err := newRunGen(t).run(&out, outputDir, args)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("run() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+
+			if out.String() != tt.out {
+				t.Errorf("run() printed\n%s\nwant\n%s", out.String(), tt.out)
+			}
+			dir := outputDir
+			if tt.dir != "" {
+				dir = filepath.Join(root, tt.dir)
+			}
+			for _, file := range tt.files {
+				if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+					t.Errorf("run() didn't generate %s: %v", file, err)
+				}
+			}
+			if len(tt.files) == 0 {
+				if _, err := os.Stat(outputDir); err == nil {
+					t.Errorf("run() generated %s", outputDir)
+				}
+			}
+		})
+	}
+}
+
+func TestRunReportJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := newRunGen(t).run(&out, t.TempDir(), []string{"report", "-json"}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("run() printed invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(report.Mappings) != 1 {
+		t.Fatalf("run() reported %d mappings, want 1", len(report.Mappings))
+	}
+
+	mapping := report.Mappings[0]
+	if mapping.Source != "api.Account" || mapping.Target != "models.Account" || mapping.To != "To" {
+		t.Errorf("run() reported %s -> %s (%s)", mapping.Source, mapping.Target, mapping.To)
+	}
+	want := map[string]FieldStatus{"ID": FieldDirect, "UserName": FieldRenamed, "Email": FieldOmitted, "Balance": FieldDirect, "CreatedAt": FieldDirect}
+	for _, field := range mapping.Fields {
+		if field.Status != want[field.Source] || field.ZeroedInTo != (field.Source == "Email") {
+			t.Errorf("run() reported %+v, want %s", field, want[field.Source])
+		}
+		delete(want, field.Source)
+	}
+	for source := range want {
+		t.Errorf("run() didn't report %s", source)
+	}
+	if !strings.Contains(out.String(), `"status": "renamed"`) {
+		t.Errorf("run() printed unindented JSON:\n%s", out.String())
+	}
+}
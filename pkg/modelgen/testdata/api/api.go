@@ -0,0 +1,52 @@
+// Package api holds the source types of the package's tests
+package api
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Account struct {
+	ID        int64
+	UserName  string
+	Email     string `json:"email"`
+	Balance   int64
+	CreatedAt time.Time
+}
+
+type Numbers struct {
+	Small    int16
+	Signed   int
+	Unsigned uint64
+	Float    float64
+	Float32  float32
+	Wide     float64
+	Huge     float64
+	Text     string
+}
+
+type Item struct {
+	ID      int
+	At      *time.Time
+	Stamps  []time.Time
+	Notes   map[string]sql.NullString
+	Created time.Time
+}
+
+type Post struct {
+	Title  string
+	Plain  *Plain
+	Plains map[string][]Plain
+}
+
+// Plain isn't registered by the tests
+type Plain struct {
+	B    []int
+	Next *Plain
+}
+
+type Session struct {
+	User  string
+	Agent string
+	token string
+}
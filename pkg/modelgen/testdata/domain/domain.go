@@ -0,0 +1,6 @@
+// Package domain holds the existing target types of the package's tests
+package domain
+
+type Item struct {
+	ID int
+}
@@ -0,0 +1,188 @@
+package modelgen
+
+import (
+	"fmt"
+	"go/parser"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// TimeFormat is how a time field is carried on the target, see ConvertTime
+type TimeFormat = types.TimeFormat
+
+const (
+	TimeKeep       = types.TimeKeep       // as is, opts fields out of WithTimeFormats
+	TimeUnix       = types.TimeUnix       // time.Time as int64 seconds since the Unix epoch
+	TimeUnixMilli  = types.TimeUnixMilli  // time.Time as int64 milliseconds since the Unix epoch
+	TimeRFC3339    = types.TimeRFC3339    // time.Time as an RFC 3339 string, with fractional seconds if any
+	TimeValue      = types.TimeValue      // *time.Time as time.Time
+	DurationMillis = types.DurationMillis // time.Duration as int64 milliseconds
+)
+
+// timeFormatNames names the formats in errors
+var timeFormatNames = map[TimeFormat]string{
+	TimeUnix:       "TimeUnix",
+	TimeUnixMilli:  "TimeUnixMilli",
+	TimeRFC3339:    "TimeRFC3339",
+	TimeValue:      "TimeValue",
+	DurationMillis: "DurationMillis",
+}
+
+// TimeFormats sets how time fields are carried on targets, see WithTimeFormats
+type TimeFormats struct {
+	Time     TimeFormat    // time.Time and *time.Time fields, TimeValue only applies to pointers
+	Duration TimeFormat    // time.Duration and *time.Duration fields
+	Policy   ConvertPolicy // what To does with values it can't convert back, see ConvertTime
+}
+
+// timeConversion is a time field format as passed to ConvertTime, resolved by Build
+type timeConversion struct {
+	format TimeFormat
+	policy ConvertPolicy
+}
+
+// WithTimeFormats converts the time fields of every mapping registered afterwards as ConvertTime does,
+// ConvertTime(field, TimeKeep) opts fields out
+//
+// Existing targets are only affected where their field has the type the format converts to
+func (m *ModelGen) WithTimeFormats(formats TimeFormats) *ModelGen {
+	m.timeFormats = &formats
+	return m
+}
+
+// ConvertTime carries a time field on the target as a number or a string: time.Time (or *time.Time) with TimeUnix,
+// TimeUnixMilli or TimeRFC3339, time.Duration (or *time.Duration) with DurationMillis. TimeValue turns a *time.Time
+// into a time.Time
+//
+// Zero times and durations (and nil pointers) convert to 0 or "" and back. Times come back in UTC. policy decides what
+// To does with values it can't convert back: strings that aren't RFC 3339 times, milliseconds overflowing a time.Duration
+func (b *MappingBuilder) ConvertTime(field string, format TimeFormat, policy ...ConvertPolicy) *MappingBuilder {
+	var p ConvertPolicy
+	if len(policy) > 0 {
+		p = policy[0]
+	}
+
+	if b.times == nil {
+		b.times = make(map[string]timeConversion)
+	}
+	b.times[field] = timeConversion{format: format, policy: p}
+	return b
+}
+
+// resolveTimes resolves the time fields converted with ConvertTime or WithTimeFormats into conversions,
+// and derefs for TimeValue
+func (b *MappingBuilder) resolveTimes(sourceInfo, targetInfo *types.StructInfo) error {
+	times := make(map[string]timeConversion)
+	if b.timeFormats != nil {
+		for _, targetField := range b.targetFields(targetInfo) {
+			path := targetField.Source
+			if path == "" {
+				continue
+			}
+			owner, field, ok := b.sourceField(sourceInfo, path)
+			if !ok {
+				continue
+			}
+
+			kind := timeKind(owner, field)
+			format := b.timeFormats.Time
+			if strings.HasSuffix(kind, "Duration") {
+				format = b.timeFormats.Duration
+			}
+			if kind == "" || format == TimeKeep || (format == TimeValue && kind != "*time.Time") {
+				continue
+			}
+			if b.target != nil && fieldKind(*targetField) != timeTarget(format) {
+				continue
+			}
+			times[path] = timeConversion{format: format, policy: b.timeFormats.Policy}
+		}
+	}
+	for path, t := range b.times {
+		times[path] = t
+	}
+
+	for path, t := range times {
+		if t.format == TimeKeep {
+			continue
+		}
+
+		owner, field, ok := b.sourceField(sourceInfo, path)
+		if !ok {
+			return fmt.Errorf("%s: can't convert %s, no such field", targetInfo.TypeName, path)
+		}
+		kind := timeKind(owner, field)
+		switch t.format {
+		case TimeUnix, TimeUnixMilli, TimeRFC3339:
+			ok = kind == "time.Time" || kind == "*time.Time"
+		case TimeValue:
+			ok = kind == "*time.Time"
+		case DurationMillis:
+			ok = kind == "time.Duration" || kind == "*time.Duration"
+		}
+		if !ok {
+			return fmt.Errorf("%s: can't convert %s with %s, it's %s", targetInfo.TypeName, path, timeFormatNames[t.format], field.Type)
+		}
+
+		if t.format == TimeValue {
+			if b.derefs == nil {
+				b.derefs = make(map[string]DerefOptions)
+			}
+			b.derefs[path] = DerefOptions{NilZero: true}
+			continue
+		}
+		b.conversions = append(b.conversions, conversion{field: path, policy: t.policy, time: t.format, kind: kind})
+	}
+	return nil
+}
+
+// targetFields returns every field of a target, including grouped ones
+func (b *MappingBuilder) targetFields(targetInfo *types.StructInfo) []*types.FieldInfo {
+	fields := make([]*types.FieldInfo, 0, len(targetInfo.Fields))
+	for i := range targetInfo.Fields {
+		fields = append(fields, &targetInfo.Fields[i])
+	}
+	for _, group := range b.config.Groups {
+		for i := range group.Type.Fields {
+			fields = append(fields, &group.Type.Fields[i])
+		}
+	}
+	return fields
+}
+
+// timeKind returns the time type of a field, eg: "*time.Time", "" if it isn't a time.Time, time.Duration or a pointer to them
+func timeKind(owner *types.StructInfo, field types.FieldInfo) string {
+	elem, isPtr := strings.CutPrefix(field.Type, "*")
+	expr, err := parser.ParseExpr(elem)
+	if err != nil {
+		return ""
+	}
+	pkg, name, ok := qualifiedName(expr)
+	if !ok || owner.Imports[pkg] != "time" || (name != "Time" && name != "Duration") {
+		return ""
+	}
+	if isPtr {
+		return "*time." + name
+	}
+	return "time." + name
+}
+
+// timeTarget returns the type a time format carries fields as
+func timeTarget(format TimeFormat) string {
+	switch format {
+	case TimeRFC3339:
+		return "string"
+	case TimeValue:
+		return "time.Time"
+	}
+	return "int64"
+}
+
+// fieldKind returns the predeclared (underlying) type of a field, or its type
+func fieldKind(field types.FieldInfo) string {
+	if field.Underlying != "" && field.Underlying != "struct" {
+		return field.Underlying
+	}
+	return field.Type
+}
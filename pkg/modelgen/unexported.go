@@ -0,0 +1,152 @@
+package modelgen
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// UnexportedPolicy decides what Build does with unexported fields, which generated code can only read from
+// the package it's generated into
+type UnexportedPolicy int
+
+const (
+	UnexportedSkip      UnexportedPolicy = iota // leave out the fields it can't read, listed as warnings by the report (default)
+	UnexportedFail                              // fail on the fields it can't read
+	UnexportedAccessors                         // as UnexportedSkip, with a getter and a setter on the target for the fields it can read, eg: Secret() and SetSecret(v)
+)
+
+// accessorConflicts are the methods generated on targets, which accessors can't be named after
+var accessorConflicts = map[string]bool{
+	"From": true, "To": true, "ToWith": true, "FromE": true, "ToE": true, "ToWithE": true,
+	"ApplyTo": true, "Clone": true, "Equal": true, "Diff": true, "Validate": true,
+}
+
+// WithUnexported sets what every mapping registered afterwards does with unexported fields, UnexportedSkip by default
+func (m *ModelGen) WithUnexported(policy UnexportedPolicy) *ModelGen {
+	m.unexported = policy
+	return m
+}
+
+// WithUnexported sets what the mapping does with unexported fields, defaults to the parent's
+//
+// Unexported fields can only be read when the struct holding them is declared in the target package (see
+// WithTargetPath), eg: a target generated into its source package
+func (b *MappingBuilder) WithUnexported(policy UnexportedPolicy) *MappingBuilder {
+	b.unexported = policy
+	return b
+}
+
+// readable reports whether the generated code can read the unexported fields of a struct, that is whether it's
+// declared in the target package
+//
+// Without WithTargetPath the package is only known by name, Generate checks the guess
+func (m *ModelGen) readable(info *types.StructInfo) bool {
+	if m.targetPath != "" {
+		return info.PackagePath == m.targetPath
+	}
+	return info.PackageName == m.targetPackage
+}
+
+// skipUnexported applies the policy to an unexported field of owner (at path in the struct typeName) the generated
+// code can't read, failing or warning that it's left out
+func (b *MappingBuilder) skipUnexported(typeName, path string, owner *types.StructInfo) error {
+	if b.unexported == UnexportedFail {
+		return fmt.Errorf("%s: can't access unexported field %s outside package %s, see WithUnexported",
+			typeName, path, owner.PackageName)
+	}
+	b.config.Warnings = append(b.config.Warnings,
+		fmt.Sprintf("%s: skipped unexported field %s, it can't be accessed outside package %s", typeName, path, owner.PackageName))
+	return nil
+}
+
+// omitUnexported leaves out the unexported fields of the source and existing target the generated code can't
+// read, for MapBetween; generated targets leave them out as their fields are added, see addTargetFields
+func (b *MappingBuilder) omitUnexported(sourceInfo, targetInfo *types.StructInfo) error {
+	if !b.parent.readable(sourceInfo) {
+		for _, field := range sourceInfo.Fields {
+			if _, mapped := b.config.FieldMap[field.Name]; mapped || token.IsExported(field.Name) || b.config.OmitFields[field.Name] {
+				continue
+			}
+			if err := b.skipUnexported(sourceInfo.TypeName, field.Name, sourceInfo); err != nil {
+				return err
+			}
+			b.config.OmitFields[field.Name] = true
+		}
+	}
+
+	if !b.parent.readable(targetInfo) {
+		for _, field := range targetInfo.Fields {
+			if token.IsExported(field.Name) || b.config.IgnoreFields[field.Name] {
+				continue
+			}
+			if err := b.skipUnexported(targetInfo.TypeName, field.Name, targetInfo); err != nil {
+				return err
+			}
+			b.config.IgnoreFields[field.Name] = true
+		}
+	}
+	return nil
+}
+
+// resolveAccessors lists the unexported target fields generated with a getter and a setter
+func (b *MappingBuilder) resolveAccessors(targetInfo *types.StructInfo) error {
+	if b.unexported != UnexportedAccessors {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	for _, field := range targetInfo.Fields {
+		names[field.Name] = true
+	}
+	for _, field := range targetInfo.Fields {
+		if field.Source == "" || token.IsExported(field.Name) || field.Name == "_" {
+			continue
+		}
+		getter := strings.ToUpper(field.Name[:1]) + field.Name[1:]
+		if !token.IsExported(getter) {
+			// eg: _secret
+			continue
+		}
+		for _, method := range []string{getter, "Set" + getter} {
+			if names[method] || accessorConflicts[method] {
+				return fmt.Errorf("%s: can't generate %s for %s, the target already has a %s", targetInfo.TypeName, method, field.Name, method)
+			}
+		}
+		b.config.Accessors = append(b.config.Accessors, field.Name)
+	}
+	return nil
+}
+
+// unreadableField returns the first field a mapping reads or writes that the package it's generated into (at
+// targetPath) can't access, empty if there's none
+func unreadableField(config types.MappingConfig, targetPath string) string {
+	var fields []types.FieldInfo
+	fields = append(fields, config.TargetType.Fields...)
+	for _, group := range config.Groups {
+		fields = append(fields, group.Type.Fields...)
+	}
+
+	for _, field := range fields {
+		if field.Source == "" {
+			continue
+		}
+		if config.ExistingTarget && !token.IsExported(field.Name) && config.TargetType.PackagePath != targetPath {
+			return config.TargetType.TypeName + "." + field.Name
+		}
+
+		owner := config.SourceType
+		parts := strings.Split(field.Source, ".")
+		for i, part := range parts {
+			if i > 0 {
+				owner = config.NestedTypes[strings.Join(parts[:i], ".")]
+			}
+			if owner != nil && !token.IsExported(part) && owner.PackagePath != targetPath {
+				return config.SourceType.TypeName + "." + strings.Join(parts[:i+1], ".")
+			}
+		}
+	}
+	return ""
+}
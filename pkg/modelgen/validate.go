@@ -0,0 +1,490 @@
+package modelgen
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/matt0792/modelgen/internal/generator"
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+// Rule is a check the generated Validate runs on a target field, see Required, MinLen, MaxLen, Min, Max, Range,
+// Match and OneOf
+//
+// Pointers are checked by the value they point to, nil pointers only fail Required
+type Rule struct {
+	kind     types.RuleKind
+	min, max interface{} // bounds of lengths and ranges, nil for no bound
+	pattern  string
+	desc     string // what pattern matches, see types.Rule
+	values   []interface{}
+}
+
+// Required fails zero values, eg: "", 0, nil, empty optionals
+func Required() Rule {
+	return Rule{kind: types.RuleRequired}
+}
+
+// MinLen fails strings with fewer than n characters, and slices and maps with fewer than n items
+func MinLen(n int) Rule {
+	return Rule{kind: types.RuleLen, min: n}
+}
+
+// MaxLen fails strings with more than n characters, and slices and maps with more than n items
+func MaxLen(n int) Rule {
+	return Rule{kind: types.RuleLen, max: n}
+}
+
+// Min fails numbers below min
+func Min(min interface{}) Rule {
+	return Rule{kind: types.RuleRange, min: min}
+}
+
+// Max fails numbers above max
+func Max(max interface{}) Rule {
+	return Rule{kind: types.RuleRange, max: max}
+}
+
+// Range fails numbers below min or above max
+func Range(min, max interface{}) Rule {
+	return Rule{kind: types.RuleRange, min: min, max: max}
+}
+
+// Match fails strings that don't match a regular expression (see regexp), eg: Match(`^[a-z0-9-]+$`)
+func Match(pattern string) Rule {
+	return Rule{kind: types.RuleMatch, pattern: pattern}
+}
+
+// OneOf fails values other than the ones listed, eg: OneOf("admin", "member")
+func OneOf(values ...interface{}) Rule {
+	return Rule{kind: types.RuleOneOf, values: values}
+}
+
+// ValidateOptions configures Validate, see WithValidate
+type ValidateOptions struct {
+	// FromE validates the model it returns, generating FromE if the mapping has none
+	FromE bool
+
+	// Tag translates the go-playground style rules of a source struct tag (eg: "validate" for
+	// `validate:"required,max=64"`) into rules, checked before the ones passed to Validate. Rules passed to Validate
+	// replace the tag rules checking the same, eg: MaxLen(5) replaces max=64 but not min=2
+	//
+	// required, omitempty, min, max, len, gte, lte, oneof, email, uuid, alpha, alphanum and numeric are supported,
+	// other rules are skipped and listed as warnings by the report
+	Tag string
+}
+
+// Validate generates Validate() error on the target, which checks a field with rules (eg: Validate("Name",
+// Required(), MaxLen(64))) and returns every failure as a *ValidationError, joined
+//
+// field is a source field path as for Omit, failures report the path of the target field. Registered nested
+// types with Validate are validated too, directly or as the items of slices and maps.
+// ValidationError is generated once into the target package
+func (b *MappingBuilder) Validate(field string, rules ...Rule) *MappingBuilder {
+	if b.rules == nil {
+		b.rules = make(map[string][]Rule)
+	}
+	b.rules[field] = append(b.rules[field], rules...)
+	b.config.Validate = true
+	return b
+}
+
+// WithValidate generates Validate() error on the target without rules of its own, for the registered nested types
+// it holds, and configures it
+func (b *MappingBuilder) WithValidate(opts ...ValidateOptions) *MappingBuilder {
+	var opt ValidateOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	b.config.Validate = true
+	b.config.ValidateFromE = opt.FromE
+	b.validateTag = opt.Tag
+	return b
+}
+
+// resolveRules resolves the rules of Validate, checking they're well formed and apply to the type of their field
+func (b *MappingBuilder) resolveRules(sourceInfo, targetInfo *types.StructInfo) error {
+	b.config.Checked = b.config.Checked || b.config.ValidateFromE
+
+	mapped := make(map[string]bool)
+	classes := make(map[string]string)
+	fieldRules := make(map[string][]Rule)
+	for _, targetField := range b.targetFields(targetInfo) {
+		path := targetField.Source
+		mapped[path] = true
+		classes[path] = b.ruleClass(path, *targetField)
+		if b.validateTag == "" || path == "" {
+			continue
+		}
+		_, field, ok := b.sourceField(sourceInfo, path)
+		if !ok {
+			continue
+		}
+		rules, skipped := b.tagRules(reflect.StructTag(field.Tag).Get(b.validateTag), path, *targetField)
+		fieldRules[path] = rules
+		for _, reason := range skipped {
+			b.config.Warnings = append(b.config.Warnings,
+				fmt.Sprintf("%s: skipped %s tag rule %s", targetInfo.TypeName, b.validateTag, reason))
+		}
+	}
+	for field, rules := range b.rules {
+		fieldRules[field] = mergeRules(fieldRules[field], rules, classes[field])
+	}
+	if len(fieldRules) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(fieldRules))
+	for field := range fieldRules {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	b.config.Rules = make(map[string][]types.Rule)
+	name := strings.ToLower(targetInfo.TypeName[:1]) + targetInfo.TypeName[1:]
+	for _, field := range fields {
+		if !mapped[field] {
+			return fmt.Errorf("%s: can't validate %s, no such field", targetInfo.TypeName, field)
+		}
+
+		patterns := 0
+		kind, optional := b.ruleKind(sourceInfo, field)
+		for _, r := range fieldRules[field] {
+			rule, err := r.resolve()
+			if err == nil {
+				err = generator.CheckRule(rule, kind, optional)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: can't validate %s: %w", targetInfo.TypeName, field, err)
+			}
+			if rule.Kind == types.RuleMatch {
+				patterns++
+				rule.Var = name + strings.ReplaceAll(field, ".", "") + "Pattern"
+				if patterns > 1 {
+					rule.Var += strconv.Itoa(patterns)
+				}
+			}
+			b.config.Rules[field] = append(b.config.Rules[field], rule)
+		}
+	}
+	return nil
+}
+
+// ruleKind returns the kind rules check a field as, as the target carries it (see generator.RuleKind), and whether
+// it's an optional
+func (b *MappingBuilder) ruleKind(sourceInfo *types.StructInfo, path string) (string, bool) {
+	if _, ok := b.config.Optionals[path]; ok {
+		return "", true
+	}
+	if enumMap, ok := b.config.EnumMaps[path]; ok {
+		return enumMap.Underlying, false
+	}
+	if conversion, ok := b.config.Conversions[path]; ok {
+		return conversion.TargetKind, false
+	}
+
+	_, field, ok := b.sourceField(sourceInfo, path)
+	if !ok {
+		return "", false
+	}
+	typ, underlying := field.Type, field.Underlying
+	if deref, ok := b.config.Derefs[path]; ok {
+		typ, underlying = strings.TrimPrefix(typ, "*"), deref.Underlying
+	}
+	return generator.RuleKind(typ, underlying), false
+}
+
+// mergeRules returns the rules of a validate tag followed by the rules passed to Validate, without the tag rules
+// checking what the others check, see ruleChecks
+//
+// Tag rules with both bounds (eg: len=4) keep the bound the others don't check, eg: the max of len=4 with MinLen(1)
+func mergeRules(tagRules, rules []Rule, class string) []Rule {
+	checked := make(map[string]bool)
+	for _, rule := range rules {
+		for _, check := range ruleChecks(rule, class) {
+			checked[check] = true
+		}
+	}
+
+	var merged []Rule
+	for _, rule := range tagRules {
+		if rule.min != nil && rule.max != nil {
+			minRule, maxRule := rule, rule
+			minRule.max, maxRule.min = nil, nil
+			minReplaced, maxReplaced := replaced(minRule, class, checked), replaced(maxRule, class, checked)
+			switch {
+			case minReplaced && !maxReplaced:
+				merged = append(merged, maxRule)
+				continue
+			case maxReplaced && !minReplaced:
+				merged = append(merged, minRule)
+				continue
+			}
+		}
+		if !replaced(rule, class, checked) {
+			merged = append(merged, rule)
+		}
+	}
+	return append(merged, rules...)
+}
+
+// replaced reports whether another rule checks something a rule checks, see ruleChecks
+func replaced(rule Rule, class string, checked map[string]bool) bool {
+	for _, check := range ruleChecks(rule, class) {
+		if checked[check] {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleChecks returns what a rule checks on a field of a class (see ruleClass): its kind, and which bounds for lengths
+// and ranges, eg: "len max" for MaxLen and max=64 on a string
+func ruleChecks(rule Rule, class string) []string {
+	kind := rule.kind
+	if kind == types.RuleSize {
+		kind = types.RuleLen
+		if class == "number" {
+			kind = types.RuleRange
+		}
+	}
+
+	name := ruleKindNames[kind]
+	if kind != types.RuleLen && kind != types.RuleRange {
+		return []string{name}
+	}
+	var checks []string
+	if rule.min != nil {
+		checks = append(checks, name+" min")
+	}
+	if rule.max != nil {
+		checks = append(checks, name+" max")
+	}
+	return checks
+}
+
+// ruleKindNames name rule kinds in ruleChecks
+var ruleKindNames = map[types.RuleKind]string{
+	types.RuleRequired:  "required",
+	types.RuleLen:       "len",
+	types.RuleRange:     "range",
+	types.RuleMatch:     "match",
+	types.RuleOneOf:     "oneof",
+	types.RuleOmitEmpty: "omitempty",
+}
+
+// resolve renders the values of a rule as Go literals
+func (r Rule) resolve() (types.Rule, error) {
+	rule := types.Rule{Kind: r.kind, Pattern: r.pattern, Desc: r.desc}
+	switch r.kind {
+	case types.RuleLen:
+		for _, bound := range []interface{}{r.min, r.max} {
+			if n, ok := bound.(int); ok && n < 0 {
+				return types.Rule{}, fmt.Errorf("negative length %d", n)
+			}
+		}
+		rule.Min, rule.Max = ruleLiteral(r.min), ruleLiteral(r.max)
+
+	case types.RuleRange, types.RuleSize:
+		for _, bound := range []interface{}{r.min, r.max} {
+			if bound != nil && valueClass(reflect.TypeOf(bound).Kind().String()) != "number" {
+				return types.Rule{}, fmt.Errorf("bound %v is not a number", bound)
+			}
+		}
+		if r.min != nil && r.max != nil && toFloat(r.max) < toFloat(r.min) {
+			return types.Rule{}, fmt.Errorf("range %v to %v is empty", r.min, r.max)
+		}
+		rule.Min, rule.Max = ruleLiteral(r.min), ruleLiteral(r.max)
+
+	case types.RuleMatch:
+		if _, err := regexp.Compile(r.pattern); err != nil {
+			return types.Rule{}, err
+		}
+
+	case types.RuleOneOf:
+		if len(r.values) == 0 {
+			return types.Rule{}, fmt.Errorf("OneOf needs values")
+		}
+		for _, v := range r.values {
+			if v == nil || valueClass(reflect.TypeOf(v).Kind().String()) == "" {
+				return types.Rule{}, fmt.Errorf("%v is not a string, a number or a bool", v)
+			}
+			rule.Values = append(rule.Values, ruleLiteral(v))
+		}
+	}
+	return rule, nil
+}
+
+// ruleLiteral renders a basic value as a Go literal, empty for nil
+func ruleLiteral(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return literal(reflect.ValueOf(v))
+}
+
+// toFloat returns a number as a float64, to order bounds of different types
+func toFloat(v interface{}) float64 {
+	return reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0))).Float()
+}
+
+// tagPatterns are the validate tag rules translated into Match
+var tagPatterns = map[string]Rule{
+	"email":    {kind: types.RuleMatch, pattern: `^[^@\s]+@[^@\s]+\.[^@\s]+$`, desc: "a valid email address"},
+	"uuid":     {kind: types.RuleMatch, pattern: `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`, desc: "a UUID"},
+	"alpha":    {kind: types.RuleMatch, pattern: `^[a-zA-Z]+$`, desc: "alphabetic"},
+	"alphanum": {kind: types.RuleMatch, pattern: `^[a-zA-Z0-9]+$`, desc: "alphanumeric"},
+	"numeric":  {kind: types.RuleMatch, pattern: `^[-+]?[0-9]+(\.[0-9]+)?$`, desc: "numeric"},
+}
+
+// tagRules translates the go-playground style rules of a validate tag into rules, along with why it skipped the others
+func (b *MappingBuilder) tagRules(tag, path string, targetField types.FieldInfo) ([]Rule, []string) {
+	if tag == "" || tag == "-" {
+		return nil, nil
+	}
+
+	kind := b.ruleClass(path, targetField)
+	var rules []Rule
+	var skipped []string
+	skip := func(part, reason string) {
+		skipped = append(skipped, fmt.Sprintf("%s of %s, %s", part, path, reason))
+	}
+	parts := strings.Split(tag, ",")
+	for i, part := range parts {
+		name, param, _ := strings.Cut(part, "=")
+		switch name {
+		case "":
+		case "dive":
+			// the rules after dive check the items, items with a generated Validate are still checked by it
+			skip(strings.Join(parts[i:], ","), "rules on items aren't supported")
+			return rules, skipped
+		case "required":
+			rules = append(rules, Required())
+		case "omitempty":
+			rules = append(rules, Rule{kind: types.RuleOmitEmpty})
+
+		case "min", "gte", "max", "lte", "len":
+			bound, ok := tagNumber(param)
+			switch {
+			case kind != "number" && kind != "string" && kind != "slice" && kind != "map":
+				skip(part, "it doesn't apply to "+targetField.Type)
+				continue
+			case !ok:
+				skip(part, param+" is not a number")
+				continue
+			}
+			rule := Rule{kind: types.RuleSize}
+			if name != "max" && name != "lte" {
+				rule.min = bound
+			}
+			if name != "min" && name != "gte" {
+				rule.max = bound
+			}
+			rules = append(rules, rule)
+
+		case "oneof":
+			if kind != "number" && kind != "string" {
+				skip(part, "it doesn't apply to "+targetField.Type)
+				continue
+			}
+			values, ok := tagValues(param, kind)
+			if !ok {
+				skip(part, "its values don't parse")
+				continue
+			}
+			rules = append(rules, OneOf(values...))
+
+		default:
+			rule, ok := tagPatterns[name]
+			switch {
+			case !ok || param != "":
+				skip(part, "it isn't supported")
+			case kind != "string":
+				skip(part, "it doesn't apply to "+targetField.Type)
+			default:
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, skipped
+}
+
+// ruleClass returns the class of value a target field holds for the validate tag rules: "number", "string",
+// "slice" or "map", pointers by the value they point to; empty for other types
+func (b *MappingBuilder) ruleClass(path string, targetField types.FieldInfo) string {
+	// optional types only tell whether they hold a value
+	if _, ok := b.config.Optionals[path]; ok {
+		return ""
+	}
+	if enumMap, ok := b.config.EnumMaps[path]; ok {
+		return valueClass(enumMap.Underlying)
+	}
+
+	kind := strings.TrimPrefix(fieldKind(targetField), "*")
+	switch {
+	case kind == "slice", kind == "map":
+		return kind
+	case strings.HasPrefix(kind, "[]"):
+		return "slice"
+	case strings.HasPrefix(kind, "map["):
+		return "map"
+	case valueClass(kind) == "bool":
+		return ""
+	}
+	return valueClass(kind)
+}
+
+// tagValues parses the space separated values of a oneof rule, as numbers for the number class
+//
+// Values may be single quoted to hold spaces, eg: oneof='in progress' done
+func tagValues(param, class string) ([]interface{}, bool) {
+	var words []string
+	for param != "" {
+		param = strings.TrimLeft(param, " ")
+		if strings.HasPrefix(param, "'") {
+			word, rest, ok := strings.Cut(param[1:], "'")
+			if !ok {
+				return nil, false
+			}
+			words, param = append(words, word), rest
+			continue
+		}
+		word, rest, _ := strings.Cut(param, " ")
+		if word != "" {
+			words = append(words, word)
+		}
+		param = rest
+	}
+	if len(words) == 0 {
+		return nil, false
+	}
+
+	values := make([]interface{}, len(words))
+	for i, word := range words {
+		values[i] = word
+		if class == "number" {
+			n, ok := tagNumber(word)
+			if !ok {
+				return nil, false
+			}
+			values[i] = n
+		}
+	}
+	return values, true
+}
+
+// tagNumber parses a number of a validate tag, as an int64 if it's an integer
+func tagNumber(s string) (interface{}, bool) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	return nil, false
+}
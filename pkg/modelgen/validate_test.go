@@ -0,0 +1,102 @@
+package modelgen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/matt0792/modelgen/internal/types"
+)
+
+func TestMergeRules(t *testing.T) {
+	tagMin := Rule{kind: types.RuleSize, min: int64(2)}
+	tagMax := Rule{kind: types.RuleSize, max: int64(64)}
+	tagLen := Rule{kind: types.RuleSize, min: int64(5), max: int64(5)}
+	omitEmpty := Rule{kind: types.RuleOmitEmpty}
+	email := tagPatterns["email"]
+
+	tests := []struct {
+		name  string
+		tag   []Rule
+		rules []Rule
+		class string
+		want  []Rule
+	}{
+		{
+			name:  "explicit rules replace tag rules of the same kind",
+			tag:   []Rule{Required(), tagMax},
+			rules: []Rule{Required(), MaxLen(5)},
+			class: "string",
+			want:  []Rule{Required(), MaxLen(5)},
+		},
+		{
+			name:  "bounds are replaced one by one",
+			tag:   []Rule{tagMin, tagMax},
+			rules: []Rule{MaxLen(5)},
+			class: "string",
+			want:  []Rule{tagMin, MaxLen(5)},
+		},
+		{
+			name:  "tag rules with both bounds keep the other bound",
+			tag:   []Rule{tagLen},
+			rules: []Rule{MinLen(1)},
+			class: "slice",
+			want:  []Rule{{kind: types.RuleSize, max: int64(5)}, MinLen(1)},
+		},
+		{
+			name:  "tag rules with both bounds are replaced by both",
+			tag:   []Rule{tagLen},
+			rules: []Rule{MinLen(1), MaxLen(4)},
+			class: "string",
+			want:  []Rule{MinLen(1), MaxLen(4)},
+		},
+		{
+			name:  "tag ranges with both bounds keep the other bound",
+			tag:   []Rule{tagLen},
+			rules: []Rule{Max(10)},
+			class: "number",
+			want:  []Rule{{kind: types.RuleSize, min: int64(5)}, Max(10)},
+		},
+		{
+			name:  "tag sizes of numbers are ranges",
+			tag:   []Rule{tagMin, tagMax},
+			rules: []Rule{Max(10)},
+			class: "number",
+			want:  []Rule{tagMin, Max(10)},
+		},
+		{
+			name:  "lengths don't replace ranges",
+			tag:   []Rule{tagMax},
+			rules: []Rule{MaxLen(5)},
+			class: "number",
+			want:  []Rule{tagMax, MaxLen(5)},
+		},
+		{
+			name:  "patterns replace tag patterns",
+			tag:   []Rule{omitEmpty, email},
+			rules: []Rule{Match(`^.+@example\.com$`)},
+			class: "string",
+			want:  []Rule{omitEmpty, Match(`^.+@example\.com$`)},
+		},
+		{
+			name:  "other kinds are kept",
+			tag:   []Rule{Required(), OneOf("a", "b")},
+			rules: []Rule{MinLen(1)},
+			class: "string",
+			want:  []Rule{Required(), OneOf("a", "b"), MinLen(1)},
+		},
+		{
+			name:  "without a tag",
+			rules: []Rule{Required()},
+			class: "string",
+			want:  []Rule{Required()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeRules(tt.tag, tt.rules, tt.class); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}